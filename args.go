@@ -1,27 +1,52 @@
 package main
 
 import (
+	"log"
+	"os"
 	"strings"
 	"sync"
 
 	"github.com/pborman/getopt/v2"
 
 	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
 )
 
 var once sync.Once
 
+// genOptions 生成控制参数，配置文件与命令行两种模式均可使用
+var genOptions struct {
+	Force  bool // 忽略生成清单，重新生成全部表
+	DryRun bool // 只在内存中渲染，输出变化汇总，不写入磁盘
+	Diff   bool // dry-run 时输出 unified diff
+	Check  bool // 校验磁盘上的生成代码是否最新，不一致时以非零状态退出
+	Prune  bool // 删除库中已不存在的表遗留的生成文件
+
+	Snapshot string // 从 gentol inspect 导出的 schema 快照读取表结构，不连接数据库
+	SQLPath  string // 从建表 SQL 文件或目录解析表结构，不连接数据库
+}
+
 type Gentol struct {
 	CurrentPkg string
 }
 
 func argHandler() {
+	force := getopt.BoolLong("force", 0, "regenerate all tables, ignoring .gentol/manifest.json")
+	dryRun := getopt.BoolLong("dry-run", 0, "render in memory and print a summary of created/modified/unchanged/skipped files, write nothing")
+	diff := getopt.BoolLong("diff", 0, "print a unified diff against the files on disk, implies --dry-run")
+	templateDir := getopt.StringLong("template_dir", 0, "", "directory of <name>.tmpl files overriding built-in templates, see 'gentol templates export'")
+	prune := getopt.BoolLong("prune", 0, "delete generated files of tables that no longer exist, asks before touching _ext/_hook files")
+	check := getopt.BoolLong("check", 0, "exit non-zero if any generated file is missing or stale, write nothing")
+	snapshot := getopt.StringLong("from-snapshot", 0, "", "read tables from a schema snapshot written by 'gentol inspect' instead of connecting to the database")
+	sqlPath := getopt.StringLong("from-sql", 0, "", "read tables from a .sql file or a directory of .sql migrations (mysql/postgres/dm DDL) instead of connecting to the database")
+
 	exist := IsExist("./conf/table.yaml")
 	if !exist {
 		exist = IsExist("./table.yaml")
 	}
 	if exist {
 		configx.Init()
+		getopt.CommandLine.Parse(knownArgs(getopt.CommandLine, os.Args))
 	} else {
 		var (
 			dbType = getopt.StringLong("db_type", 0, "postgres", "database type such as [mysql, sqlserver, postgres, oracle, greenplum etc. ]")
@@ -34,10 +59,10 @@ func argHandler() {
 			password = getopt.StringLong("password", 'P', "", "db password, if there is a dsn, ignore it")
 			database = getopt.StringLong("database", 'd', "", "database to for db table")
 
-			module = getopt.StringLong("module", 'm', "", "module name for go project")
-			schema = getopt.StringLong("schema", 's', "", "schema to for db table")
-			table  = getopt.StringLong("table", 't', "", "table name to build struct from")
-			// templateDir = getopt.StringLong("template_dir", 0, "./template", "Template Dir")
+			module  = getopt.StringLong("module", 'm', "", "module name for go project")
+			schema  = getopt.StringLong("schema", 's', "", "schema to for db table")
+			table   = getopt.StringLong("table", 't', "", "table name to build struct from")
+			exclude = getopt.StringLong("exclude", 0, "", "comma separated table patterns to skip, glob or re:<regexp>, e.g. '*_bak,schema_migrations'")
 
 			modelPath   = getopt.StringLong("model", 0, "dal/db/model", "name to set for model package")
 			daoPath     = getopt.StringLong("dao", 0, "dal/db/dao", "name to set for dao package")
@@ -47,11 +72,16 @@ func argHandler() {
 
 			jsonNameFormat  = getopt.StringLong("json_format", 0, "snake", "json name format [snake | upper_camel | lower_camel]")
 			protoNameFormat = getopt.StringLong("protobuf_format", 0, "snake", "proto name format [snake | upper_camel | lower_camel]")
+			extraTags       = getopt.StringLong("tags", 0, "", "comma separated extra struct tags named like json_format, e.g. xml,yaml,form")
+			validateTag     = getopt.BoolLong("validate_tag", 0, "add go-playground/validator tags derived from column constraints")
 			// gogoProtoImport = getopt.StringLong("gogoproto", 0, "", "location of gogo import ")
 
 			onlyModel             = getopt.BoolLong("only_model", 0, "overwrite existing files (default)", "disable overwriting files")
 			useHook               = getopt.BoolLong("gen_hook", 0, "disable gorm hook file (default)", "gorm hook file")
 			useSQLNullable        = getopt.BoolLong("use_sql_nullable", 0, "use sql.Null if use_sql_nullable true, default use guregu")
+			readWriteSplit        = getopt.BoolLong("read_write_split", 0, "route generated Select*/Count* to replicas, writes and transactions to primary")
+			useOtel               = getopt.BoolLong("use_otel", 0, "register OpenTelemetry tracing and latency metrics in generated db.go")
+			tenantMode            = getopt.StringLong("tenant_mode", 0, "", "resolve table per tenant from context [schema | suffix]")
 			addProtobufAnnotation = getopt.BoolLong("proto", 0, "add protobuf annotations (tags)", "")
 			runGoFmt              = getopt.BoolLong("rungofmt", 0, "run gofmt on output dir", "")
 			DefaultDBName         = "_default_db_"
@@ -67,6 +97,13 @@ func argHandler() {
 		configx.TableConfigs.RunGoFmt = *runGoFmt
 		configx.TableConfigs.JsonFormat = *jsonNameFormat
 		configx.TableConfigs.ProtobufFormat = *protoNameFormat
+		configx.TableConfigs.ValidateTag = *validateTag
+		if *extraTags != "" {
+			for _, tagName := range strings.Split(*extraTags, ",") {
+				configx.TableConfigs.Tags = append(configx.TableConfigs.Tags,
+					&metadata.TagOption{Name: strings.TrimSpace(tagName), Format: *jsonNameFormat})
+			}
+		}
 		configx.TableConfigs.GoModule = *module
 		databaseConfig := &configx.DBTableInfo{
 			DBName:         DefaultDBName,
@@ -83,6 +120,9 @@ func argHandler() {
 			Password:       *password,
 			Database:       *database,
 			UseSQLNullable: *useSQLNullable,
+			ReadWriteSplit: *readWriteSplit,
+			UseOtel:        *useOtel,
+			TenantMode:     *tenantMode,
 			Tables: []*configx.TableInfo{
 				{
 					SchemaName: *schema,
@@ -92,13 +132,90 @@ func argHandler() {
 						}
 						return []string{}
 					}(),
+					Exclude: func() []string {
+						if *exclude != "" {
+							return strings.Split(*exclude, ",")
+						}
+						return nil
+					}(),
 				},
 			},
 		}
+		if err := databaseConfig.CheckTenantMode(); err != nil {
+			log.Fatalf("--tenant_mode=%s is invalid, use schema or suffix", *tenantMode)
+		}
 		databaseConfig.GenDSN()
 		configx.TableConfigs.Configs = []*configx.DBTableInfo{
 			databaseConfig,
 		}
 	}
+	genOptions.Force = *force
+	if *templateDir != "" {
+		configx.TableConfigs.TemplateDir = *templateDir
+	}
+	genOptions.Diff = *diff
+	genOptions.DryRun = *dryRun || *diff
+	genOptions.Prune = *prune
+	genOptions.Snapshot = *snapshot
+	genOptions.SQLPath = *sqlPath
+	if genOptions.Snapshot != "" && genOptions.SQLPath != "" {
+		log.Fatal("--from-snapshot and --from-sql cannot be used together")
+	}
+	genOptions.Check = genOptions.Check || *check
+	if genOptions.Check {
+		// 校验需要完整渲染所有表，不能依赖生成清单跳过
+		genOptions.Force = true
+		genOptions.DryRun = true
+	}
 	// handleDB()
 }
+
+// knownArgs 配置文件模式下只保留 set 中定义的参数：连接及生成配置来自 table.yaml，
+// 沿用命令行模式的参数（如 --db_type mysql）时忽略并提示，不中断生成
+func knownArgs(set *getopt.Set, args []string) []string {
+	// Lookup 对未定义的参数返回非 nil 的空接口值，按名称建立索引
+	options := make(map[string]getopt.Option)
+	set.VisitAll(func(opt getopt.Option) {
+		if opt.LongName() != "" {
+			options["--"+opt.LongName()] = opt
+		}
+		if opt.ShortName() != "" {
+			options["-"+opt.ShortName()] = opt
+		}
+	})
+
+	result := args[:1:1]
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+		var (
+			name     string
+			hasValue bool
+		)
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue = strings.Cut(arg, "=")
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			name, hasValue = arg[:2], len(arg) > 2
+		default:
+			result = append(result, arg)
+			continue
+		}
+		if opt, ok := options[name]; ok {
+			result = append(result, arg)
+			if !opt.IsFlag() && !hasValue && i+1 < len(args) {
+				i++
+				result = append(result, args[i])
+			}
+			continue
+		}
+		log.Printf("ignore %s: configured by table.yaml", arg)
+		// 未定义参数以空格分隔的值一并忽略
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pborman/getopt/v2"
+)
+
+func TestKnownArgs(t *testing.T) {
+	set := getopt.New()
+	set.BoolLong("force", 0, "")
+	set.BoolLong("dry-run", 0, "")
+	set.StringLong("from-sql", 0, "", "")
+	set.StringLong("output", 'o', "", "")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"gentol"}, []string{"gentol"}},
+		{[]string{"gentol", "--force", "--dry-run"}, []string{"gentol", "--force", "--dry-run"}},
+		// 命令行模式的参数在配置文件模式下忽略，连同其值
+		{[]string{"gentol", "--db_type", "mysql", "--force"}, []string{"gentol", "--force"}},
+		{[]string{"gentol", "--db_type=mysql", "--force"}, []string{"gentol", "--force"}},
+		{[]string{"gentol", "--only_model", "--force"}, []string{"gentol", "--force"}},
+		{[]string{"gentol", "-t", "user", "--dry-run"}, []string{"gentol", "--dry-run"}},
+		{[]string{"gentol", "-tuser", "--dry-run"}, []string{"gentol", "--dry-run"}},
+		// 已定义参数以空格分隔的值保留，即使以 - 开头
+		{[]string{"gentol", "--from-sql", "db.sql", "--force"}, []string{"gentol", "--from-sql", "db.sql", "--force"}},
+		{[]string{"gentol", "-o", "-", "--schema", "public"}, []string{"gentol", "-o", "-"}},
+		{[]string{"gentol", "-oout.json"}, []string{"gentol", "-oout.json"}},
+		{[]string{"gentol", "--", "--db_type"}, []string{"gentol", "--", "--db_type"}},
+	}
+	for _, tt := range tests {
+		if got := knownArgs(set, tt.args); !slices.Equal(got, tt.want) {
+			t.Errorf("knownArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	// 过滤后的参数可以正常解析
+	set.Parse(knownArgs(set, []string{"gentol", "--db_type", "mysql", "--from-sql=db.sql", "--force"}))
+	if !set.IsSet("force") || set.Lookup("from-sql").String() != "db.sql" {
+		t.Errorf("parsed force=%v from-sql=%q", set.IsSet("force"), set.Lookup("from-sql").String())
+	}
+}
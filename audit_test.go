@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// auditFixtureModel 与生成的审计钩子一致的模型
+const auditFixtureModel = `package model
+
+import "gorm.io/gorm"
+
+type Order struct {
+	ID     int64   ` + "`gorm:\"primaryKey;column:id\"`" + `
+	Amount float64 ` + "`gorm:\"column:amount\"`" + `
+}
+
+func (o *Order) TableName() string {
+	return "order"
+}
+
+func (o *Order) BeforeUpdate(tx *gorm.DB) error {
+	return AuditBeforeChange(tx, o)
+}
+
+func (o *Order) AfterUpdate(tx *gorm.DB) error {
+	return AuditAfterUpdate(tx, o)
+}
+
+func (o *Order) BeforeDelete(tx *gorm.DB) error {
+	return AuditBeforeChange(tx, o)
+}
+
+func (o *Order) AfterDelete(tx *gorm.DB) error {
+	return AuditAfterDelete(tx, o)
+}
+`
+
+// auditFixtureTest 在 SQLite 上按 DAO 的方式（Table 路由 + Model + Where + Updates）更新分表、租户表，检查审计日志的前后镜像
+const auditFixtureTest = `package model
+
+import (
+	"testing"
+
+	"github.com/jasonlabz/sqlite"
+	"gorm.io/gorm"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func exec(t *testing.T, db *gorm.DB, sql string) {
+	if err := db.Exec(sql).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func lastAudit(t *testing.T, db *gorm.DB) AuditLog {
+	var log AuditLog
+	if err := db.Order("id desc").First(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func checkAudit(t *testing.T, db *gorm.DB, table, operation, before, after string) {
+	t.Helper()
+	log := lastAudit(t, db)
+	if log.TargetTable != table || log.Operation != operation || log.BeforeData != before || log.AfterData != after {
+		t.Fatalf("audit log = %s %s %q -> %q, want %s %s %q -> %q",
+			log.TargetTable, log.Operation, log.BeforeData, log.AfterData, table, operation, before, after)
+	}
+}
+
+func TestAuditShardTable(t *testing.T) {
+	db := openDB(t)
+	exec(t, db, "CREATE TABLE order_202401 (id INTEGER PRIMARY KEY, amount REAL)")
+	exec(t, db, "INSERT INTO order_202401 VALUES (1, 10)")
+
+	err := db.Table("order_202401").Model(&Order{}).Where(map[string]any{"id": 1}).
+		Updates(map[string]any{"amount": 20}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAudit(t, db, "order_202401", "update", ` + "`" + `{"ID":1,"Amount":10}` + "`" + `, ` + "`" + `{"ID":1,"Amount":20}` + "`" + `)
+
+	if err := db.Table("order_202401").Where(map[string]any{"id": 1}).Delete(&Order{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	checkAudit(t, db, "order_202401", "delete", ` + "`" + `{"ID":1,"Amount":20}` + "`" + `, "")
+}
+
+func TestAuditTenantSchema(t *testing.T) {
+	db := openDB(t)
+	exec(t, db, "ATTACH DATABASE ':memory:' AS tenant_a")
+	exec(t, db, "CREATE TABLE main.` + "`order`" + ` (id INTEGER PRIMARY KEY, amount REAL)")
+	exec(t, db, "CREATE TABLE tenant_a.` + "`order`" + ` (id INTEGER PRIMARY KEY, amount REAL)")
+	exec(t, db, "INSERT INTO main.` + "`order`" + ` VALUES (1, 99)")
+	exec(t, db, "INSERT INTO tenant_a.` + "`order`" + ` VALUES (1, 10)")
+
+	// 多租户 DAO 的 routed 切换到 table(ctx) 返回的 "tenant.table"，并记录该表供审计日志使用
+	err := db.Table("tenant_a.order").Set(AuditTableKey, "tenant_a.order").Model(&Order{}).
+		Where(map[string]any{"id": 1}).Updates(map[string]any{"amount": 20}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAudit(t, db, "tenant_a.order", "update", ` + "`" + `{"ID":1,"Amount":10}` + "`" + `, ` + "`" + `{"ID":1,"Amount":20}` + "`" + `)
+
+	// 不同租户的同名表在审计日志中可以区分
+	err = db.Table("order").Set(AuditTableKey, "main.order").Model(&Order{}).
+		Where(map[string]any{"id": 1}).Updates(map[string]any{"amount": 100}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAudit(t, db, "main.order", "update", ` + "`" + `{"ID":1,"Amount":99}` + "`" + `, ` + "`" + `{"ID":1,"Amount":100}` + "`" + `)
+}
+
+func TestAuditTenantSuffix(t *testing.T) {
+	db := openDB(t)
+	exec(t, db, "CREATE TABLE order_t1 (id INTEGER PRIMARY KEY, amount REAL)")
+	exec(t, db, "INSERT INTO order_t1 VALUES (1, 10)")
+
+	err := db.Table("order_t1").Model(&Order{}).Where(map[string]any{"id": 1}).
+		Updates(map[string]any{"amount": 20}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAudit(t, db, "order_t1", "update", ` + "`" + `{"ID":1,"Amount":10}` + "`" + `, ` + "`" + `{"ID":1,"Amount":20}` + "`" + `)
+}
+`
+
+// TestGeneratedAudit 生成审计模型，在独立模块中对 SQLite 运行审计钩子
+func TestGeneratedAudit(t *testing.T) {
+	dir := t.TempDir()
+	WriteAudit(&configx.DBTableInfo{DBType: "sqlite", ModelPath: filepath.Join(dir, "model")})
+	writeTestFile(t, filepath.Join(dir, "model", "order.go"), auditFixtureModel)
+	writeTestFile(t, filepath.Join(dir, "model", "order_test.go"), auditFixtureTest)
+	runGeneratedTests(t, dir)
+}
+
+// runGeneratedTests 以本仓库的依赖版本为 dir 建立模块并运行其中的测试
+func runGeneratedTests(t *testing.T, dir string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	writeTestModule(t, dir)
+
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test in generated module failed: %v\n%s", err, output)
+	}
+}
+
+// testModuleMod 本仓库的 go.mod、go.sum，模块名替换为 gentoltest；在切换工作目录前读取
+var testModuleMod, testModuleSum = func() (string, string) {
+	goMod, _ := os.ReadFile("go.mod")
+	goSum, _ := os.ReadFile("go.sum")
+	return regexp.MustCompile(`(?m)^module .*$`).ReplaceAllString(string(goMod), "module gentoltest"), string(goSum)
+}()
+
+// writeTestModule 在 dir 中建立 gentoltest 模块，沿用本仓库的依赖版本及 go.sum，无需联网
+func writeTestModule(t *testing.T, dir string) {
+	t.Helper()
+	if testModuleMod == "" {
+		t.Fatal("go.mod of gentol not found")
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), testModuleMod)
+	writeTestFile(t, filepath.Join(dir, "go.sum"), testModuleSum)
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDaoRoutedAuditTable 审计表在多租户、分表模式下由 DAO 把路由后的表名交给审计钩子
+func TestDaoRoutedAuditTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbInfo  *configx.DBTableInfo
+		routed  bool
+		audited bool
+	}{
+		{"tenant audited", &configx.DBTableInfo{TenantMode: "schema", Audit: &configx.AuditInfo{Tables: []string{"order"}}}, true, true},
+		{"tenant not audited", &configx.DBTableInfo{TenantMode: "schema"}, true, false},
+		{"plain audited", &configx.DBTableInfo{Audit: &configx.AuditInfo{Tables: []string{"order"}}}, false, false},
+	}
+	tpl, ok := loadTpl("dao_impl")
+	if !ok {
+		t.Fatal("dao_impl template not found")
+	}
+	for _, tt := range tests {
+		tt.dbInfo.ModelPath, tt.dbInfo.DaoPath = "dal/db/model", "dal/db/dao"
+		data := newDaoMeta(tt.dbInfo, "public", "order", "", nil).GenRenderData()
+		tmpl, err := template.New("dao_impl").Funcs(metadata.FuncMap()).Option("missingkey=error").Parse(tpl.Content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		content := buf.String()
+		if got := strings.Contains(content, "o.routed(ctx, dao.DB())"); got != tt.routed {
+			t.Errorf("%s: tx routes through routed = %v, want %v", tt.name, got, tt.routed)
+		}
+		if got := strings.Contains(content, ".Set(model.AuditTableKey, table)"); got != tt.audited {
+			t.Errorf("%s: routed records the audit table = %v, want %v", tt.name, got, tt.audited)
+		}
+	}
+}
+
+// TestGeneratedAuditHooks 审计的表生成调用审计函数的钩子，并生成审计日志模型及建表语句；库中已有的审计日志表不按普通表生成
+func TestGeneratedAuditHooks(t *testing.T) {
+	dir := generateFromSQLite(t, &configx.DBTableInfo{Audit: &configx.AuditInfo{Tables: []string{"order"}}},
+		`CREATE TABLE "order" (id INTEGER PRIMARY KEY, amount REAL)`,
+		"CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)",
+		"CREATE TABLE audit_log (id INTEGER PRIMARY KEY)")
+	modelDir := filepath.Join(dir, "dal", "db", "model")
+
+	hook := readGenerated(t, filepath.Join(modelDir, "order_hook.go"))
+	for _, want := range []string{
+		"return AuditAfterCreate(tx, o)",
+		"return AuditBeforeChange(tx, o)",
+		"return AuditAfterUpdate(tx, o)",
+		"return AuditAfterDelete(tx, o)",
+	} {
+		if !strings.Contains(hook, want) {
+			t.Errorf("order_hook.go should contain %s", want)
+		}
+	}
+	if IsExist(filepath.Join(modelDir, "user_hook.go")) {
+		t.Error("user is not audited and gen_hook is off, no hook file should be generated")
+	}
+	if audit := readGenerated(t, filepath.Join(modelDir, "audit_log.go")); !strings.Contains(audit, "func AuditAfterUpdate[T any](") {
+		t.Error("audit_log.go should define the audit functions")
+	}
+	if ddl := readGenerated(t, filepath.Join(modelDir, "audit_log.sql")); !strings.Contains(ddl, "audit_log") {
+		t.Errorf("audit_log.sql should create the audit table:\n%s", ddl)
+	}
+	if IsExist(filepath.Join(dir, "dal", "db", "dao", "audit_log_dao.go")) {
+		t.Error("the audit table should not get a generated dao")
+	}
+}
@@ -1,15 +1,21 @@
 package configx
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 
 	"github.com/jasonlabz/gentol/gormx"
+	"github.com/jasonlabz/gentol/metadata"
 )
 
 var DefaultPath = "conf/table.yaml"
@@ -29,7 +35,13 @@ type DBTableInfo struct {
 	ModelPath      string       `json:"model_path" yaml:"model_path"`
 	DaoPath        string       `json:"dao_path" yaml:"dao_path"`
 	UseSQLNullable bool         `json:"use_sql_nullable" yaml:"use_sql_nullable"`
+	ReadWriteSplit bool         `json:"read_write_split" yaml:"read_write_split"` // 生成读写分离路由：查询走从库，写入及事务走主库
+	UseOtel        bool         `json:"use_otel" yaml:"use_otel"`                 // 生成 OpenTelemetry 链路追踪与耗时指标
+	TenantMode     string       `json:"tenant_mode" yaml:"tenant_mode"`           // 多租户表路由：schema（按租户切换 schema）| suffix（租户作为表名后缀）
 	Tables         []*TableInfo `json:"tables" yaml:"tables"`
+	Audit          *AuditInfo   `json:"audit" yaml:"audit"`   // 审计配置，列出的表生成前后镜像审计钩子
+	Shards         []*ShardInfo `json:"shards" yaml:"shards"` // 分表配置，匹配的物理表合并为一个逻辑表生成
+	Naming         *NamingInfo  `json:"naming" yaml:"naming"` // 命名策略，控制结构体名与文件名
 
 	ModelModule string
 	DaoModule   string
@@ -43,6 +55,21 @@ type DBTableInfo struct {
 	Database string `json:"database" yaml:"database"`
 }
 
+// 多租户表路由方式
+const (
+	TenantModeSchema = "schema" // 按租户切换 schema
+	TenantModeSuffix = "suffix" // 租户标识作为表名后缀
+)
+
+// CheckTenantMode 校验多租户路由方式，只允许为空、schema、suffix
+func (c *DBTableInfo) CheckTenantMode() error {
+	switch c.TenantMode {
+	case "", TenantModeSchema, TenantModeSuffix:
+		return nil
+	}
+	return fmt.Errorf("db %s: invalid tenant_mode %q, use schema or suffix", c.DBName, c.TenantMode)
+}
+
 func (c *DBTableInfo) GenDSN() (dsn string) {
 	if c.DSN != "" {
 		return c.DSN
@@ -66,19 +93,340 @@ func (c *DBTableInfo) GenDSN() (dsn string) {
 type TableInfo struct {
 	SchemaName string   `json:"schema_name" yaml:"schema_name"`
 	TableList  []string `json:"table_list" yaml:"table_list"`
+	Include    []string `json:"include" yaml:"include"` // 包含的表，支持 glob（biz_*），re: 前缀表示正则
+	Exclude    []string `json:"exclude" yaml:"exclude"` // 排除的表，语法同 include
+
+	includeMatchers []func(string) bool
+	excludeMatchers []func(string) bool
+}
+
+// CompileFilters 校验并编译 include/exclude 规则
+func (t *TableInfo) CompileFilters() (err error) {
+	if t.includeMatchers, err = compileTablePatterns(t.Include); err != nil {
+		return
+	}
+	t.excludeMatchers, err = compileTablePatterns(t.Exclude)
+	return
+}
+
+// Excluded 判断表是否被 exclude 规则排除
+func (t *TableInfo) Excluded(tableName string) bool {
+	return matchAny(t.excludeMatchers, tableName)
+}
+
+// Accept 判断表是否需要生成：未被排除，且 include 为空或命中 include
+func (t *TableInfo) Accept(tableName string) bool {
+	if t.Excluded(tableName) {
+		return false
+	}
+	return len(t.includeMatchers) == 0 || matchAny(t.includeMatchers, tableName)
+}
+
+// compileTablePatterns 编译表名规则，re: 前缀为正则，其余按 glob 匹配
+func compileTablePatterns(patterns []string) ([]func(string) bool, error) {
+	matchers := make([]func(string) bool, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+			regex, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+			}
+			matchers = append(matchers, regex.MatchString)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+		matchers = append(matchers, func(tableName string) bool {
+			matched, _ := path.Match(pattern, tableName)
+			return matched
+		})
+	}
+	return matchers, nil
+}
+
+func matchAny(matchers []func(string) bool, tableName string) bool {
+	for _, match := range matchers {
+		if match(tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditInfo 审计配置
+type AuditInfo struct {
+	TableName string   `json:"table_name" yaml:"table_name"` // 审计日志表名，默认 audit_log
+	Tables    []string `json:"tables" yaml:"tables"`         // 需要审计的表
+}
+
+// GetTableName 返回审计日志表名
+func (a *AuditInfo) GetTableName() string {
+	if a == nil || a.TableName == "" {
+		return metadata.DefaultAuditTableName
+	}
+	return a.TableName
+}
+
+// IsAudited 判断表是否需要生成审计钩子
+func (a *AuditInfo) IsAudited(tableName string) bool {
+	if a == nil {
+		return false
+	}
+	for _, table := range a.Tables {
+		if strings.Trim(table, "\"") == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// ShardInfo 分表配置
+type ShardInfo struct {
+	TableName string `json:"table_name" yaml:"table_name"` // 逻辑表名，生成的 model/dao 以此命名
+	Pattern   string `json:"pattern" yaml:"pattern"`       // 物理表名正则，如 ^order_\d{6}$
+	Format    string `json:"format" yaml:"format"`         // 由分表键生成物理表名的格式，默认 {table_name}_%s
+
+	regex *regexp.Regexp
+}
+
+// GetFormat 返回物理表名格式
+func (s *ShardInfo) GetFormat() string {
+	if s.Format == "" {
+		return s.TableName + "_%s"
+	}
+	return s.Format
+}
+
+// Match 判断物理表是否属于该分表
+func (s *ShardInfo) Match(tableName string) bool {
+	return s.regex != nil && s.regex.MatchString(tableName)
+}
+
+// CompileShards 校验并编译分表配置
+func (c *DBTableInfo) CompileShards() error {
+	for _, shard := range c.Shards {
+		if shard.TableName == "" || shard.Pattern == "" {
+			return fmt.Errorf("shard config of db %s: table_name and pattern are required", c.DBName)
+		}
+		regex, err := regexp.Compile(shard.Pattern)
+		if err != nil {
+			return fmt.Errorf("shard %s: invalid pattern: %w", shard.TableName, err)
+		}
+		if strings.Count(shard.GetFormat(), "%s") != 1 {
+			return fmt.Errorf("shard %s: format must contain exactly one %%s", shard.TableName)
+		}
+		shard.regex = regex
+	}
+	return nil
+}
+
+// MatchShard 返回物理表所属的分表配置，不属于任何分表时返回 nil
+func (c *DBTableInfo) MatchShard(tableName string) *ShardInfo {
+	for _, shard := range c.Shards {
+		if shard.Match(tableName) {
+			return shard
+		}
+	}
+	return nil
+}
+
+// GetShard 返回逻辑表对应的分表配置
+func (c *DBTableInfo) GetShard(tableName string) *ShardInfo {
+	for _, shard := range c.Shards {
+		if shard.TableName == tableName {
+			return shard
+		}
+	}
+	return nil
+}
+
+// NamingInfo 命名策略
+type NamingInfo struct {
+	StripPrefix   []string          `json:"strip_prefix" yaml:"strip_prefix"`   // 生成结构体名前去掉的表名前缀，如 t_、tb_
+	Singularize   bool              `json:"singularize" yaml:"singularize"`     // 结构体名转为单数，如 order_items -> OrderItem
+	FileTemplate  string            `json:"file_name" yaml:"file_name"`         // 文件名模板（不含后缀），可用 {{.TableName}} {{.StructName}} {{.SnakeName}}，默认 {{.TableName}}
+	StructNames   map[string]string `json:"struct_names" yaml:"struct_names"`   // 按表指定结构体名，优先级最高
+	Abbreviations []string          `json:"abbreviations" yaml:"abbreviations"` // 追加的缩写词，如 SKU、OAuth
+
+	fileNameTpl *template.Template
+}
+
+// Compile 校验文件名模板并登记缩写词
+func (n *NamingInfo) Compile() (err error) {
+	if n == nil {
+		return
+	}
+	metadata.RegisterAbbreviations(n.Abbreviations...)
+	if n.FileTemplate == "" {
+		return
+	}
+	n.fileNameTpl, err = template.New("file_name").Option("missingkey=error").Parse(n.FileTemplate)
+	if err != nil {
+		return fmt.Errorf("naming.file_name: %w", err)
+	}
+	return
+}
+
+// StructName 返回表对应的结构体名
+func (n *NamingInfo) StructName(tableName string) string {
+	if n == nil {
+		return metadata.UnderscoreToUpperCamelCase(tableName)
+	}
+	if structName, ok := n.StructNames[tableName]; ok && structName != "" {
+		return structName
+	}
+	name := tableName
+	for _, prefix := range n.StripPrefix {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			name = strings.TrimPrefix(name, prefix)
+			break
+		}
+	}
+	if n.Singularize {
+		name = metadata.Singularize(name)
+	}
+	return metadata.UnderscoreToUpperCamelCase(name)
+}
+
+// FileName 返回表对应的文件名（不含 .go 及 _dao 等后缀）
+func (n *NamingInfo) FileName(tableName, structName string) string {
+	if n == nil || n.fileNameTpl == nil {
+		return tableName
+	}
+	var buf bytes.Buffer
+	err := n.fileNameTpl.Execute(&buf, map[string]string{
+		"TableName":  tableName,
+		"StructName": structName,
+		"SnakeName":  metadata.CamelCaseToUnderscore(structName),
+	})
+	if err != nil || buf.Len() == 0 {
+		log.Printf("naming.file_name render failed for %s: %v, fallback to table name", tableName, err)
+		return tableName
+	}
+	return buf.String()
+}
+
+// 自定义输出的生成范围
+const (
+	OutputScopeTable    = "table"    // 每张表生成一个文件
+	OutputScopeSchema   = "schema"   // 每个 schema 生成一个文件
+	OutputScopeDatabase = "database" // 每个库生成一个文件
+)
+
+// 自定义输出的覆盖策略
+const (
+	OverwriteAlways = "always" // 每次生成都覆盖（保护区内容保留）
+	OverwriteOnce   = "once"   // 仅首次生成，记录在生成清单中，删除后也不再生成
+	OverwriteNever  = "never"  // 从不覆盖已有文件，文件不存在时生成
+)
+
+// OutputInfo 自定义输出：使用指定模板为每张表、每个 schema 或每个库额外生成文件
+type OutputInfo struct {
+	Template  string `json:"template" yaml:"template"`   // 模板文件路径
+	Path      string `json:"path" yaml:"path"`           // 输出路径模板，如 api/{{.TableName}}_handler.go
+	Scope     string `json:"scope" yaml:"scope"`         // 生成范围：table（默认）| schema | database
+	Overwrite string `json:"overwrite" yaml:"overwrite"` // 覆盖策略：always（默认）| once | never
+
+	pathTpl *template.Template
+}
+
+// Compile 校验配置并解析输出路径模板
+func (o *OutputInfo) Compile() (err error) {
+	switch o.GetScope() {
+	case OutputScopeTable, OutputScopeSchema, OutputScopeDatabase:
+	default:
+		return fmt.Errorf("output %s: invalid scope %q", o.Template, o.Scope)
+	}
+	switch o.GetOverwrite() {
+	case OverwriteAlways, OverwriteOnce, OverwriteNever:
+	default:
+		return fmt.Errorf("output %s: invalid overwrite %q", o.Template, o.Overwrite)
+	}
+	if o.Template == "" || o.Path == "" {
+		return fmt.Errorf("output requires both template and path")
+	}
+	o.pathTpl, err = template.New("path").Option("missingkey=error").Parse(o.Path)
+	if err != nil {
+		return fmt.Errorf("output %s: invalid path: %v", o.Template, err)
+	}
+	return nil
+}
+
+// GetScope 生成范围，默认 table
+func (o *OutputInfo) GetScope() string {
+	if o.Scope == "" {
+		return OutputScopeTable
+	}
+	return o.Scope
+}
+
+// GetOverwrite 覆盖策略，默认 always
+func (o *OutputInfo) GetOverwrite() string {
+	if o.Overwrite == "" {
+		return OverwriteAlways
+	}
+	return o.Overwrite
+}
+
+// OutputPath 按渲染数据生成输出路径
+func (o *OutputInfo) OutputPath(data map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := o.pathTpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// PluginInfo 外部生成器插件：执行 gentol-gen-<name>，经 stdin 传入表结构及配置（JSON），从 stdout 读取生成的文件
+type PluginInfo struct {
+	Name    string         `json:"name" yaml:"name"`       // 插件名，默认在 PATH 中查找 gentol-gen-<name>
+	Path    string         `json:"path" yaml:"path"`       // 可选，插件可执行文件路径
+	Options map[string]any `json:"options" yaml:"options"` // 原样传给插件的参数
+}
+
+// Executable 插件可执行文件
+func (p *PluginInfo) Executable() string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return "gentol-gen-" + p.Name
 }
 
 type config struct {
-	Configs               []*DBTableInfo `json:"configs" yaml:"configs"`
-	JsonFormat            string         `json:"json_format" yaml:"json_format"`
-	ProtobufFormat        string         `json:"protobuf_format" yaml:"protobuf_format"`
-	GoModule              string         `json:"module" yaml:"module"`
-	RunGoFmt              bool           `json:"rungofmt" yaml:"rungofmt"`
-	AddProtobufAnnotation bool           `json:"addProtobufAnnotation" yaml:"addProtobufAnnotation"`
+	Configs               []*DBTableInfo        `json:"configs" yaml:"configs"`
+	JsonFormat            string                `json:"json_format" yaml:"json_format"`
+	XMLFormat             string                `json:"xml_format" yaml:"xml_format"`     // 非空时生成 xml 标签，等同于在 tags 中配置 xml
+	Tags                  []*metadata.TagOption `json:"tags" yaml:"tags"`                 // 额外的结构体标签，如 xml、yaml、form、mapstructure、bson
+	ValidateTag           bool                  `json:"validate_tag" yaml:"validate_tag"` // 按字段约束生成 go-playground/validator 的 validate 标签
+	ProtobufFormat        string                `json:"protobuf_format" yaml:"protobuf_format"`
+	GoModule              string                `json:"module" yaml:"module"`
+	TemplateDir           string                `json:"template_dir" yaml:"template_dir"` // 自定义模板目录，其中的 <模板名>.tmpl 覆盖同名内置模板
+	Outputs               []*OutputInfo         `json:"outputs" yaml:"outputs"`           // 自定义输出
+	Plugins               []*PluginInfo         `json:"plugins" yaml:"plugins"`           // 外部生成器插件
+	RunGoFmt              bool                  `json:"rungofmt" yaml:"rungofmt"`
+	AddProtobufAnnotation bool                  `json:"addProtobufAnnotation" yaml:"addProtobufAnnotation"`
 }
 
 var TableConfigs = new(config)
 
+// GetTags 返回需要额外生成的结构体标签，xml_format 作为 xml 标签的简写
+func (c *config) GetTags() []*metadata.TagOption {
+	tags := make([]*metadata.TagOption, 0, len(c.Tags)+1)
+	hasXML := false
+	for _, tag := range c.Tags {
+		if tag == nil || tag.Name == "" || tag.Name == "json" || tag.Name == "gorm" {
+			continue
+		}
+		hasXML = hasXML || tag.Name == "xml"
+		tags = append(tags, tag)
+	}
+	if c.XMLFormat != "" && !hasXML {
+		tags = append(tags, &metadata.TagOption{Name: "xml", Format: c.XMLFormat})
+	}
+	return tags
+}
+
 func GetConfig() *config {
 	if TableConfigs != nil {
 		return TableConfigs
@@ -96,6 +444,11 @@ func LoadConfigFromYaml(configPath string) {
 		fmt.Println("error occurred: ", err)
 		return
 	}
+	for _, dbInfo := range TableConfigs.Configs {
+		if err = dbInfo.CheckTenantMode(); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func ParseConfigByViper(configPath, configName, configType string) {
@@ -0,0 +1,231 @@
+package configx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+func TestGetTags(t *testing.T) {
+	yaml := &metadata.TagOption{Name: "yaml"}
+	xml := &metadata.TagOption{Name: "xml", Format: "upper_camel"}
+	tests := []struct {
+		name string
+		cfg  config
+		want []*metadata.TagOption
+	}{
+		{"none", config{}, []*metadata.TagOption{}},
+		{"xml_format shorthand", config{XMLFormat: "snake"}, []*metadata.TagOption{{Name: "xml", Format: "snake"}}},
+		{"explicit xml wins", config{XMLFormat: "snake", Tags: []*metadata.TagOption{yaml, xml}}, []*metadata.TagOption{yaml, xml}},
+		{
+			"skip json, gorm and empty",
+			config{Tags: []*metadata.TagOption{nil, {Name: ""}, {Name: "json"}, {Name: "gorm"}, yaml}},
+			[]*metadata.TagOption{yaml},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.GetTags(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: GetTags() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTenantMode(t *testing.T) {
+	for _, mode := range []string{"", TenantModeSchema, TenantModeSuffix} {
+		if err := (&DBTableInfo{DBName: "db", TenantMode: mode}).CheckTenantMode(); err != nil {
+			t.Errorf("CheckTenantMode(%q) = %v, want nil", mode, err)
+		}
+	}
+	for _, mode := range []string{"sufix", "Schema", "table", " suffix"} {
+		if err := (&DBTableInfo{DBName: "db", TenantMode: mode}).CheckTenantMode(); err == nil {
+			t.Errorf("CheckTenantMode(%q) = nil, want an error", mode)
+		}
+	}
+}
+
+func TestLoadConfigRejectsTenantMode(t *testing.T) {
+	saved := *TableConfigs
+	defer func() { *TableConfigs = saved }()
+
+	configPath := filepath.Join(t.TempDir(), "table.yaml")
+	content := "configs:\n  - db_name: db\n    db_type: postgres\n    tenant_mode: sufix\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("LoadConfigFromYaml with tenant_mode sufix should fail")
+		}
+	}()
+	LoadConfigFromYaml(configPath)
+}
+
+func TestAuditInfo(t *testing.T) {
+	var none *AuditInfo
+	if got := none.GetTableName(); got != metadata.DefaultAuditTableName {
+		t.Errorf("nil GetTableName() = %q, want %q", got, metadata.DefaultAuditTableName)
+	}
+	if none.IsAudited("order") {
+		t.Error("nil IsAudited(order) = true, want false")
+	}
+
+	audit := &AuditInfo{TableName: "ops_audit", Tables: []string{"order", `"User"`}}
+	if got := audit.GetTableName(); got != "ops_audit" {
+		t.Errorf("GetTableName() = %q, want ops_audit", got)
+	}
+	for table, want := range map[string]bool{"order": true, "User": true, "user": false, "order_item": false} {
+		if got := audit.IsAudited(table); got != want {
+			t.Errorf("IsAudited(%q) = %v, want %v", table, got, want)
+		}
+	}
+}
+
+func TestCompileShards(t *testing.T) {
+	tests := []struct {
+		name  string
+		shard *ShardInfo
+		ok    bool
+	}{
+		{"default format", &ShardInfo{TableName: "order", Pattern: `^order_\d{6}$`}, true},
+		{"custom format", &ShardInfo{TableName: "log", Pattern: `^log_p\d+$`, Format: "log_p%s"}, true},
+		{"missing table name", &ShardInfo{Pattern: `^order_\d+$`}, false},
+		{"missing pattern", &ShardInfo{TableName: "order"}, false},
+		{"invalid pattern", &ShardInfo{TableName: "order", Pattern: `^order_(\d+$`}, false},
+		{"format without placeholder", &ShardInfo{TableName: "order", Pattern: `^order_\d+$`, Format: "order_all"}, false},
+		{"format with two placeholders", &ShardInfo{TableName: "order", Pattern: `^order_\d+$`, Format: "order_%s_%s"}, false},
+	}
+	for _, tt := range tests {
+		err := (&DBTableInfo{DBName: "db", Shards: []*ShardInfo{tt.shard}}).CompileShards()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: CompileShards() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestMatchShard(t *testing.T) {
+	order := &ShardInfo{TableName: "order", Pattern: `^order_\d{6}$`}
+	log := &ShardInfo{TableName: "log", Pattern: `^log_p\d+$`, Format: "log_p%s"}
+	c := &DBTableInfo{DBName: "db", Shards: []*ShardInfo{order, log}}
+
+	// 编译前不匹配任何表
+	if got := c.MatchShard("order_202401"); got != nil {
+		t.Errorf("MatchShard before CompileShards = %s, want nil", got.TableName)
+	}
+	if err := c.CompileShards(); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]*ShardInfo{
+		"order_202401": order,
+		"log_p3":       log,
+		"order":        nil,
+		"order_2024":   nil,
+		"order_item":   nil,
+		"log_3":        nil,
+	}
+	for table, want := range tests {
+		if got := c.MatchShard(table); got != want {
+			t.Errorf("MatchShard(%q) = %+v, want %+v", table, got, want)
+		}
+	}
+
+	if got := c.GetShard("log"); got != log {
+		t.Errorf("GetShard(log) = %+v, want the log shard", got)
+	}
+	if got := c.GetShard("log_p3"); got != nil {
+		t.Errorf("GetShard(log_p3) = %+v, want nil for a physical table", got)
+	}
+	if got := order.GetFormat(); got != "order_%s" {
+		t.Errorf("default GetFormat() = %q, want order_%%s", got)
+	}
+	if got := log.GetFormat(); got != "log_p%s" {
+		t.Errorf("GetFormat() = %q, want log_p%%s", got)
+	}
+}
+
+func TestTableFilters(t *testing.T) {
+	info := &TableInfo{
+		Include: []string{"biz_*", "re:^(user|order)$"},
+		Exclude: []string{"*_bak", "re:^biz_tmp_"},
+	}
+	if err := info.CompileFilters(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		table            string
+		accept, excluded bool
+	}{
+		{"biz_order", true, false},
+		{"user", true, false},
+		{"order", true, false},
+		{"users", false, false},
+		{"order_item", false, false},
+		{"biz_order_bak", false, true},
+		{"biz_tmp_1", false, true},
+		{"user_bak", false, true},
+	}
+	for _, tt := range tests {
+		if got := info.Accept(tt.table); got != tt.accept {
+			t.Errorf("Accept(%q) = %v, want %v", tt.table, got, tt.accept)
+		}
+		if got := info.Excluded(tt.table); got != tt.excluded {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.table, got, tt.excluded)
+		}
+	}
+
+	// 未配置 include 时接受全部未排除的表
+	info = &TableInfo{Exclude: []string{"schema_migrations"}}
+	if err := info.CompileFilters(); err != nil {
+		t.Fatal(err)
+	}
+	if !info.Accept("anything") || info.Accept("schema_migrations") {
+		t.Error("exclude only filter should accept every table except the excluded ones")
+	}
+
+	for _, bad := range []*TableInfo{
+		{Include: []string{"re:^(user"}},
+		{Exclude: []string{"[a-"}},
+	} {
+		if err := bad.CompileFilters(); err == nil {
+			t.Errorf("CompileFilters(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestOutputInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		output *OutputInfo
+		ok     bool
+	}{
+		{"defaults", &OutputInfo{Template: "a.tmpl", Path: "api/{{.TableName}}.go"}, true},
+		{"schema once", &OutputInfo{Template: "a.tmpl", Path: "{{.SchemaName}}.md", Scope: OutputScopeSchema, Overwrite: OverwriteOnce}, true},
+		{"database never", &OutputInfo{Template: "a.tmpl", Path: "db.md", Scope: OutputScopeDatabase, Overwrite: OverwriteNever}, true},
+		{"invalid scope", &OutputInfo{Template: "a.tmpl", Path: "a.go", Scope: "column"}, false},
+		{"invalid overwrite", &OutputInfo{Template: "a.tmpl", Path: "a.go", Overwrite: "sometimes"}, false},
+		{"missing template", &OutputInfo{Path: "a.go"}, false},
+		{"missing path", &OutputInfo{Template: "a.tmpl"}, false},
+		{"invalid path", &OutputInfo{Template: "a.tmpl", Path: "{{.TableName"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.output.Compile(); (err == nil) != tt.ok {
+			t.Errorf("%s: Compile() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+
+	output := &OutputInfo{Template: "a.tmpl", Path: "api/{{.TableName}}_handler.go"}
+	if output.GetScope() != OutputScopeTable || output.GetOverwrite() != OverwriteAlways {
+		t.Errorf("default scope, overwrite = %s, %s, want table, always", output.GetScope(), output.GetOverwrite())
+	}
+	if err := output.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := output.OutputPath(map[string]any{"TableName": "user"}); err != nil || got != "api/user_handler.go" {
+		t.Errorf("OutputPath() = %q, %v, want api/user_handler.go", got, err)
+	}
+	if _, err := output.OutputPath(map[string]any{}); err == nil {
+		t.Error("OutputPath() with a missing key should fail")
+	}
+}
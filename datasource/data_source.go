@@ -44,6 +44,11 @@ func (ds *DS) GetColumnsUnderTable(ctx context.Context, dbName, logicDBName stri
 	return ds.Operator.GetColumnsUnderTables(ctx, dbName, logicDBName, tableNames)
 }
 
+// GetReadOnlyColumns 获取表中由数据库生成值的只读字段
+func (ds *DS) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	return ds.Operator.GetReadOnlyColumns(ctx, dbName, logicDBName, tableName)
+}
+
 // CreateSchema 创建逻辑库
 func (ds *DS) CreateSchema(ctx context.Context, dbName, schemaName, commentInfo string) (err error) {
 	return ds.Operator.CreateSchema(ctx, dbName, schemaName, commentInfo)
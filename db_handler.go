@@ -16,11 +16,11 @@ package main
 
 import (
 	"bufio"
-	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
@@ -40,9 +40,19 @@ func processDB() {
 func handleDB() {
 	tableConfigs := configx.TableConfigs
 
+	loadManifest()
 	for _, dbInfo := range tableConfigs.Configs {
 		processDatabaseConfig(dbInfo, tableConfigs.GoModule)
 	}
+	if genOptions.Check {
+		checkGenReport()
+		return
+	}
+	if genOptions.DryRun {
+		printGenReport()
+		return
+	}
+	saveManifest()
 }
 
 // processDatabaseConfig 处理单个数据库配置
@@ -232,11 +242,41 @@ func getRelativePath(absPath, basePath string) string {
 
 // processDatabaseTables 处理数据库表
 func processDatabaseTables(dbInfo *configx.DBTableInfo) {
-	db := createDBConnection(dbInfo)
-	ds := getDataSource(gormx.DBType(dbInfo.DBType))
+	// 提前补齐默认路径，保证各表的生成指纹稳定
+	if dbInfo.ModelPath == "" {
+		dbInfo.ModelPath = "dal/db/model"
+	}
+	if dbInfo.DaoPath == "" {
+		dbInfo.DaoPath = "dal/db/dao"
+	}
+	source := newSchemaSource(dbInfo)
+
+	if err := dbInfo.CompileShards(); err != nil {
+		panic(err)
+	}
+	if err := dbInfo.Naming.Compile(); err != nil {
+		panic(err)
+	}
+	if err := compileOutputs(); err != nil {
+		panic(err)
+	}
+	tableMap := buildTableMap(dbInfo, source)
+	shardMap := collapseShardTables(dbInfo, tableMap)
+	comments := loadTableComments(source)
+	processTables(dbInfo, source, tableMap, shardMap, comments)
+	if genOptions.Prune {
+		pruneOrphans(dbInfo, comments)
+	}
+}
 
-	tableMap := buildTableMap(dbInfo, ds, db)
-	processTables(dbInfo, db, tableMap)
+// loadTableComments 获取库中表注释：schema -> 表名 -> 注释，失败时仅打印日志
+func loadTableComments(source schemaSource) map[string]map[string]string {
+	comments, err := source.tables()
+	if err != nil {
+		log.Printf("获取表注释失败: %v", err)
+		return make(map[string]map[string]string)
+	}
+	return comments
 }
 
 // createDBConnection 创建数据库连接
@@ -264,7 +304,7 @@ func getDataSource(dbType gormx.DBType) *datasource.DS {
 }
 
 // buildTableMap 构建表映射
-func buildTableMap(dbInfo *configx.DBTableInfo, ds *datasource.DS, db *gorm.DB) map[string]map[string]bool {
+func buildTableMap(dbInfo *configx.DBTableInfo, source schemaSource) map[string]map[string]bool {
 	tableMap := make(map[string]map[string]bool)
 
 	if len(dbInfo.Tables) == 0 {
@@ -274,44 +314,53 @@ func buildTableMap(dbInfo *configx.DBTableInfo, ds *datasource.DS, db *gorm.DB)
 	}
 
 	for _, tableInfo := range dbInfo.Tables {
+		if err := tableInfo.CompileFilters(); err != nil {
+			panic(err)
+		}
 		schemaName := strings.Trim(tableInfo.SchemaName, "\"")
-		tableMap = mergeTableInfo(tableMap, schemaName, tableInfo, ds, db, dbInfo.DBName)
+		tableMap = mergeTableInfo(tableMap, schemaName, tableInfo, source)
 	}
 
 	return tableMap
 }
 
-// mergeTableInfo 合并表信息
-func mergeTableInfo(tableMap map[string]map[string]bool, schemaName string, tableInfo *configx.TableInfo, ds *datasource.DS, db *gorm.DB, dbName string) map[string]map[string]bool {
-	if len(tableInfo.TableList) == 0 {
-		return mergeAllTables(tableMap, schemaName, ds, db, dbName)
+// mergeTableInfo 合并表信息：table_list 为精确表名，include 为匹配规则，两者都为空时取全部表；exclude 对两者都生效
+func mergeTableInfo(tableMap map[string]map[string]bool, schemaName string, tableInfo *configx.TableInfo, source schemaSource) map[string]map[string]bool {
+	if len(tableInfo.TableList) == 0 || len(tableInfo.Include) > 0 {
+		tableMap = mergeAllTables(tableMap, schemaName, tableInfo, source)
 	}
-	return mergeSpecificTables(tableMap, schemaName, tableInfo.TableList)
+	return mergeSpecificTables(tableMap, schemaName, tableInfo)
 }
 
-// mergeAllTables 合并所有表
-func mergeAllTables(tableMap map[string]map[string]bool, schemaName string, ds *datasource.DS, db *gorm.DB, dbName string) map[string]map[string]bool {
-	dbTableMap, err := ds.GetTablesUnderDB(context.TODO(), dbName)
+// mergeAllTables 合并库中满足 include/exclude 规则的表
+func mergeAllTables(tableMap map[string]map[string]bool, schemaName string, tableInfo *configx.TableInfo, source schemaSource) map[string]map[string]bool {
+	dbTableMap, err := source.tables()
 	if err != nil {
 		panic(err)
 	}
 
-	for schema, dbMeta := range dbTableMap {
+	for schema, tables := range dbTableMap {
 		if schemaName != "" && schema != schemaName {
 			continue
 		}
 
-		for _, table := range dbMeta.TableInfoList {
-			addTableToMap(tableMap, schema, table.TableName)
+		for tableName := range tables {
+			if !tableInfo.Accept(tableName) {
+				continue
+			}
+			addTableToMap(tableMap, schema, tableName)
 		}
 	}
 	return tableMap
 }
 
 // mergeSpecificTables 合并指定表
-func mergeSpecificTables(tableMap map[string]map[string]bool, schemaName string, tableList []string) map[string]map[string]bool {
-	for _, tableName := range tableList {
+func mergeSpecificTables(tableMap map[string]map[string]bool, schemaName string, tableInfo *configx.TableInfo) map[string]map[string]bool {
+	for _, tableName := range tableInfo.TableList {
 		tableName = strings.Trim(tableName, "\"")
+		if tableInfo.Excluded(tableName) {
+			continue
+		}
 		addTableToMap(tableMap, schemaName, tableName)
 	}
 	return tableMap
@@ -325,33 +374,115 @@ func addTableToMap(tableMap map[string]map[string]bool, schema, tableName string
 	tableMap[schema][tableName] = true
 }
 
+// collapseShardTables 将匹配分表配置的物理表从 tableMap 中移出，按 schema -> 逻辑表 -> 物理表列表归组
+func collapseShardTables(dbInfo *configx.DBTableInfo, tableMap map[string]map[string]bool) map[string]map[string][]string {
+	shardMap := make(map[string]map[string][]string)
+	if len(dbInfo.Shards) == 0 {
+		return shardMap
+	}
+	for schema, tables := range tableMap {
+		for tableName := range tables {
+			shard := dbInfo.MatchShard(tableName)
+			if shard == nil {
+				continue
+			}
+			delete(tables, tableName)
+			if _, exists := shardMap[schema]; !exists {
+				shardMap[schema] = make(map[string][]string)
+			}
+			shardMap[schema][shard.TableName] = append(shardMap[schema][shard.TableName], tableName)
+		}
+		for logicTable, physicalTables := range shardMap[schema] {
+			// 逻辑表本身存在时（如分区父表）不重复生成
+			delete(tables, logicTable)
+			sort.Strings(physicalTables)
+		}
+	}
+	return shardMap
+}
+
+// skippedTables 因输入未变化而跳过的表数量
+var skippedTables int
+
 // processTables 处理表
-func processTables(dbInfo *configx.DBTableInfo, db *gorm.DB, tableMap map[string]map[string]bool) {
+func processTables(dbInfo *configx.DBTableInfo, source schemaSource, tableMap map[string]map[string]bool,
+	shardMap map[string]map[string][]string, comments map[string]map[string]string) {
+	skippedTables = 0
+	outputTables = make(map[string][]map[string]any)
+	schemaTables = nil
 	for schema, tables := range tableMap {
 		for tableName := range tables {
-			processSingleTable(dbInfo, db, schema, tableName)
+			// 审计日志表由 WriteAudit 单独生成
+			if dbInfo.Audit != nil && tableName == dbInfo.Audit.GetTableName() {
+				continue
+			}
+			processSingleTable(dbInfo, source, schema, tableName, tableName, comments[schema][tableName])
 		}
 	}
+	for schema, shards := range shardMap {
+		for logicTable, physicalTables := range shards {
+			// 以排序后的首张物理表作为字段结构来源
+			log.Printf("分表 %s 合并 %d 张物理表，字段取自 %s", logicTable, len(physicalTables), physicalTables[0])
+			comment := comments[schema][logicTable]
+			if comment == "" {
+				comment = comments[schema][physicalTables[0]]
+			}
+			processSingleTable(dbInfo, source, schema, logicTable, physicalTables[0], comment)
+		}
+	}
+	if dbInfo.Audit != nil && len(dbInfo.Audit.Tables) > 0 {
+		WriteAudit(dbInfo)
+	}
+	if hasGroupOutputs() {
+		WriteGroupOutputs(dbInfo)
+	}
+	if len(configx.TableConfigs.Plugins) > 0 {
+		runPlugins(dbInfo)
+	}
+	if skippedTables > 0 {
+		log.Printf("%d 张表未发生变化已跳过，使用 --force 重新生成全部", skippedTables)
+	}
 }
 
-// processSingleTable 处理单个表
-func processSingleTable(dbInfo *configx.DBTableInfo, db *gorm.DB, schema, tableName string) {
-	fullTableName := buildFullTableName(schema, tableName)
+// processSingleTable 处理单个表，sourceTable 为读取字段结构的物理表（分表时与 tableName 不同）
+func processSingleTable(dbInfo *configx.DBTableInfo, source schemaSource, schema, tableName, sourceTable, tableComment string) {
+	seenTables[manifestKey(dbInfo, schema, tableName)] = true
 
-	columnTypes, err := db.Migrator().ColumnTypes(fullTableName)
+	columnTypes, indexes, readOnlyColumns, err := source.table(schema, sourceTable)
 	if err != nil {
-		log.Printf("获取表 %s 列信息失败: %v", fullTableName, err)
+		log.Println(err)
+		genReport.Failed = append(genReport.Failed, buildFullTableName(schema, sourceTable))
 		return
 	}
-	indexes, getErr := db.Migrator().GetIndexes(fullTableName)
-	if getErr != nil {
-		log.Println(getErr)
+
+	// 输入未变化且生成文件仍在时跳过
+	key := manifestKey(dbInfo, schema, tableName)
+	hash := tableFingerprint(dbInfo, schema, tableName, sourceTable, tableComment, columnTypes, indexes, readOnlyColumns)
+	var outputData map[string]any
+	if len(configx.TableConfigs.Outputs) > 0 {
+		outputData = tableRenderData(dbInfo, schema, tableName, tableComment, columnTypes, indexes, readOnlyColumns)
+		collectOutputTable(outputData)
+	}
+	if len(configx.TableConfigs.Plugins) > 0 {
+		schemaTables = append(schemaTables, newSchemaTable(dbInfo, schema, tableName, sourceTable, tableComment,
+			columnTypes, indexes, readOnlyColumns))
+	}
+	if !genOptions.Force && manifest.upToDate(key, hash) {
+		skippedTables++
+		genReport.Skipped = append(genReport.Skipped, manifest.Tables[key].Files...)
+		return
 	}
-	WriteModel(dbInfo, schema, tableName, columnTypes, indexes)
+
+	renderedFiles = nil
+	WriteModel(dbInfo, schema, tableName, tableComment, columnTypes, indexes, readOnlyColumns)
 
 	if !dbInfo.OnlyModel {
-		WriteDao(dbInfo, schema, tableName, columnTypes)
+		WriteDao(dbInfo, schema, tableName, tableComment, columnTypes)
+	}
+	if outputData != nil {
+		WriteTableOutputs(outputData)
 	}
+	manifest.record(key, hash, renderedFiles)
 }
 
 // buildFullTableName 构建完整表名
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+func TestCollapseShardTables(t *testing.T) {
+	dbInfo := &configx.DBTableInfo{DBName: "db", Shards: []*configx.ShardInfo{
+		{TableName: "order", Pattern: `^order_\d{6}$`},
+		{TableName: "log", Pattern: `^log_p\d+$`, Format: "log_p%s"},
+	}}
+	if err := dbInfo.CompileShards(); err != nil {
+		t.Fatal(err)
+	}
+	tableMap := map[string]map[string]bool{
+		"public": {"order_202402": true, "order_202401": true, "order": true, "order_item": true, "user": true},
+		"sales":  {"order_202403": true, "log_p2": true, "log_p10": true},
+	}
+	shardMap := collapseShardTables(dbInfo, tableMap)
+
+	// 物理表及已存在的逻辑表（如分区父表）从普通表中移出
+	wantTables := map[string]map[string]bool{
+		"public": {"order_item": true, "user": true},
+		"sales":  {},
+	}
+	if !reflect.DeepEqual(tableMap, wantTables) {
+		t.Errorf("tables after collapse = %v, want %v", tableMap, wantTables)
+	}
+	wantShards := map[string]map[string][]string{
+		"public": {"order": {"order_202401", "order_202402"}},
+		"sales":  {"order": {"order_202403"}, "log": {"log_p10", "log_p2"}},
+	}
+	if !reflect.DeepEqual(shardMap, wantShards) {
+		t.Errorf("collapseShardTables() = %v, want %v", shardMap, wantShards)
+	}
+
+	// 未配置分表时不改变表
+	tableMap = map[string]map[string]bool{"public": {"order_202401": true}}
+	if shardMap = collapseShardTables(&configx.DBTableInfo{}, tableMap); len(shardMap) != 0 || !tableMap["public"]["order_202401"] {
+		t.Errorf("collapse without shards = %v, tables %v", shardMap, tableMap)
+	}
+}
+
+// TestGeneratedShardTables 同一分表族只生成一个逻辑表的 model、dao，DAO 按分表键路由到物理表
+func TestGeneratedShardTables(t *testing.T) {
+	dbInfo := &configx.DBTableInfo{Shards: []*configx.ShardInfo{{TableName: "order", Pattern: `^order_\d{6}$`}}}
+	dir := generateFromSQLite(t, dbInfo,
+		"CREATE TABLE order_202402 (id INTEGER PRIMARY KEY, amount REAL)",
+		"CREATE TABLE order_202401 (id INTEGER PRIMARY KEY, amount REAL, remark TEXT)",
+		"CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)")
+	modelDir := filepath.Join(dir, "dal", "db", "model")
+	daoDir := filepath.Join(dir, "dal", "db", "dao")
+
+	for _, physical := range []string{"order_202401", "order_202402"} {
+		if IsExist(filepath.Join(modelDir, physical+".go")) {
+			t.Errorf("physical table %s should not get its own model", physical)
+		}
+	}
+	// 字段取自排序后的首张物理表
+	model := readGenerated(t, filepath.Join(modelDir, "order.go"))
+	if !strings.Contains(model, "type Order struct") || !strings.Contains(model, "column:remark") {
+		t.Errorf("order.go should define Order with the columns of order_202401:\n%s", model)
+	}
+
+	impl := readGenerated(t, filepath.Join(daoDir, "impl", "order_dao_impl.go"))
+	methods := daoMethodBodies(impl)
+	if !strings.Contains(methods["TableFor"], `fmt.Sprintf("order_%s", key)`) {
+		t.Errorf("TableFor should format the physical table:\n%s", methods["TableFor"])
+	}
+	if !strings.Contains(methods["tx"], "o.requireShardKey(ctx, o.routed(ctx, tx))") {
+		t.Errorf("tx should route by the shard key:\n%s", methods["tx"])
+	}
+	if !strings.Contains(methods["table"], "dao.ShardKeyFromContext(ctx)") {
+		t.Errorf("table should read the shard key from context:\n%s", methods["table"])
+	}
+	if db := readGenerated(t, filepath.Join(daoDir, "db.go")); !strings.Contains(db, "func WithShardKey(") {
+		t.Error("db.go should define WithShardKey")
+	}
+	if user := readGenerated(t, filepath.Join(daoDir, "impl", "user_dao_impl.go")); strings.Contains(user, "requireShardKey") {
+		t.Error("a table outside the shard family should not require a shard key")
+	}
+}
+
+// TestGeneratedTableFilters table_list 与 include 规则取并集，exclude 对两者都生效
+func TestGeneratedTableFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *configx.TableInfo
+		want  []string
+	}{
+		{"all tables", &configx.TableInfo{}, []string{"biz_order", "biz_order_bak", "schema_migrations", "user", "user_bak"}},
+		{"exclude", &configx.TableInfo{Exclude: []string{"*_bak", "re:^schema_"}}, []string{"biz_order", "user"}},
+		{"include", &configx.TableInfo{Include: []string{"biz_*"}}, []string{"biz_order", "biz_order_bak"}},
+		{"table list", &configx.TableInfo{TableList: []string{"user", "user_bak"}}, []string{"user", "user_bak"}},
+		{
+			"table list, include and exclude",
+			&configx.TableInfo{TableList: []string{"user", "user_bak"}, Include: []string{"biz_*"}, Exclude: []string{"*_bak"}},
+			[]string{"biz_order", "user"},
+		},
+	}
+	for _, tt := range tests {
+		dir := generateFromSQLite(t, &configx.DBTableInfo{OnlyModel: true, Tables: []*configx.TableInfo{tt.table}},
+			"CREATE TABLE user (id INTEGER PRIMARY KEY)",
+			"CREATE TABLE user_bak (id INTEGER PRIMARY KEY)",
+			"CREATE TABLE biz_order (id INTEGER PRIMARY KEY)",
+			"CREATE TABLE biz_order_bak (id INTEGER PRIMARY KEY)",
+			"CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY)")
+		files, err := filepath.Glob(filepath.Join(dir, "dal", "db", "model", "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(files))
+		for _, file := range files {
+			if name := strings.TrimSuffix(filepath.Base(file), ".go"); name != "base" {
+				got = append(got, name)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: generated models = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -85,6 +85,10 @@ func (G GPOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbTabl
 			"AND tablename NOT LIKE 'pg%' " +
 			"AND tablename NOT LIKE 'gp%' " +
 			"AND tablename NOT LIKE 'sql_%' " +
+			// 分区子表归入父表，不单独列出。Greenplum 6 的分区基于继承实现，父表 relkind 为 'r' 而非 'p'
+			// （分区关系记录在 pg_partition_rule，Greenplum 7 已移除），无法像 postgres.go 一样只过滤声明式分区，
+			// 因此所有继承子表都归入父表
+			"AND NOT EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid) " +
 			"ORDER BY tb.schemaname, tb.tablename").
 		Find(&gormDBTables).Error
 	if len(gormDBTables) == 0 {
@@ -278,3 +282,32 @@ func (G GPOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement string)
 	}
 	return
 }
+
+func (G GPOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// Greenplum 7 起支持生成列，低版本无 is_generated 字段，视为没有只读列
+	var supported int64
+	err = db.WithContext(ctx).
+		Raw("SELECT count(1) FROM information_schema.columns " +
+			"WHERE table_schema = 'information_schema' AND table_name = 'columns' AND column_name = 'is_generated'").
+		Scan(&supported).Error
+	if err != nil || supported == 0 {
+		return
+	}
+	err = db.WithContext(ctx).
+		Raw("SELECT column_name FROM information_schema.columns "+
+			"WHERE table_schema = COALESCE(NULLIF(?, ''), current_schema()) "+
+			"AND table_name = ? "+
+			"AND is_generated = 'ALWAYS' "+
+			"ORDER BY ordinal_position", logicDBName, tableName).
+		Scan(&columns).Error
+	return
+}
@@ -269,3 +269,24 @@ func (m MySQLOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement stri
 	}
 	return
 }
+
+func (m MySQLOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// 虚拟列与存储生成列
+	err = db.WithContext(ctx).
+		Raw("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS "+
+			"WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) "+
+			"AND TABLE_NAME = ? "+
+			"AND (EXTRA LIKE '%VIRTUAL GENERATED%' OR EXTRA LIKE '%STORED GENERATED%') "+
+			"ORDER BY ORDINAL_POSITION", logicDBName, tableName).
+		Scan(&columns).Error
+	return
+}
@@ -25,6 +25,9 @@ type IDataExplorer interface {
 	// GetColumnsUnderTables 获取指定库表下字段列表
 	GetColumnsUnderTables(ctx context.Context, dbName, logicDBName string, tableNames []string) (tableColMap map[string]*TableColInfo, err error)
 
+	// GetReadOnlyColumns 获取表中由数据库生成值的只读字段（生成列、计算列、rowversion 等），logicDBName 为空时取当前 schema
+	GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error)
+
 	// CreateSchema 创建逻辑库
 	CreateSchema(ctx context.Context, dbName, schemaName, commentInfo string) (err error)
 
@@ -271,3 +271,24 @@ func (o OracleOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement str
 	}
 	return
 }
+
+func (o OracleOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// 虚拟列
+	err = db.WithContext(ctx).
+		Raw("SELECT COLUMN_NAME FROM ALL_TAB_COLS "+
+			"WHERE OWNER = NVL(?, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) "+
+			"AND TABLE_NAME = ? "+
+			"AND VIRTUAL_COLUMN = 'YES' AND HIDDEN_COLUMN = 'NO' "+
+			"ORDER BY COLUMN_ID", logicDBName, tableName).
+		Scan(&columns).Error
+	return
+}
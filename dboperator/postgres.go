@@ -85,6 +85,9 @@ func (P PGOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbTabl
 			"AND tablename NOT LIKE 'pg%' " +
 			"AND tablename NOT LIKE 'gp%' " +
 			"AND tablename NOT LIKE 'sql_%' " +
+			// 声明式分区的子表归入父表，不单独列出；普通继承（父表 relkind 为 'r'）的子表仍单独列出
+			"AND NOT EXISTS (SELECT 1 FROM pg_inherits i JOIN pg_class p ON p.oid = i.inhparent " +
+			"WHERE i.inhrelid = c.oid AND p.relkind = 'p') " +
 			"ORDER BY tb.schemaname, tb.tablename").
 		Find(&gormDBTables).Error
 	if len(gormDBTables) == 0 {
@@ -278,3 +281,24 @@ func (P PGOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement string)
 	}
 	return
 }
+
+func (P PGOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// GENERATED ALWAYS AS (...) STORED，PostgreSQL 12 起支持
+	err = db.WithContext(ctx).
+		Raw("SELECT column_name FROM information_schema.columns "+
+			"WHERE table_schema = COALESCE(NULLIF(?, ''), current_schema()) "+
+			"AND table_name = ? "+
+			"AND is_generated = 'ALWAYS' "+
+			"ORDER BY ordinal_position", logicDBName, tableName).
+		Scan(&columns).Error
+	return
+}
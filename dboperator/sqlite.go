@@ -239,3 +239,20 @@ func (m SQLiteOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement str
 	}
 	return
 }
+
+func (m SQLiteOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// table_xinfo 中 hidden 为 2（虚拟）或 3（存储）表示生成列
+	err = db.WithContext(ctx).
+		Raw("SELECT name FROM pragma_table_xinfo(?) WHERE hidden IN (2, 3) ORDER BY cid", tableName).
+		Scan(&columns).Error
+	return
+}
@@ -251,3 +251,28 @@ func (s SqlServerOperator) ExecuteDDL(ctx context.Context, dbName, ddlStatement
 	}
 	return
 }
+
+func (s SqlServerOperator) GetReadOnlyColumns(ctx context.Context, dbName, logicDBName, tableName string) (columns []string, err error) {
+	columns = make([]string, 0)
+	if dbName == "" {
+		err = errors.New("empty dbName")
+		return
+	}
+	db, err := gormx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// 计算列及 rowversion/timestamp 列
+	objectName := tableName
+	if logicDBName != "" {
+		objectName = fmt.Sprintf("[%s].[%s]", logicDBName, tableName)
+	}
+	err = db.WithContext(ctx).
+		Raw("SELECT c.name FROM sys.columns c "+
+			"JOIN sys.types t ON t.user_type_id = c.user_type_id "+
+			"WHERE c.object_id = OBJECT_ID(?) "+
+			"AND (c.is_computed = 1 OR t.name IN ('timestamp', 'rowversion')) "+
+			"ORDER BY c.column_id", objectName).
+		Scan(&columns).Error
+	return
+}
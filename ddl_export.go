@@ -0,0 +1,592 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pborman/getopt/v2"
+	"gorm.io/gorm/schema"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// processDDLExport ddl export 子命令：解析 Go 包中的 gorm 模型，输出目标数据库的建表语句
+func processDDLExport() {
+	os.Args = append(os.Args[:1:1], os.Args[3:]...)
+	dbType := getopt.StringLong("db_type", 0, "", "target database type [mysql | postgres | greenplum | sqlserver | oracle | dm | sqlite]")
+	output := getopt.StringLong("output", 'o', "", "write the DDL to file instead of stdout")
+	patterns := parseInterspersed()
+	if *dbType == "" {
+		log.Fatal("缺少必要参数: --db_type")
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	writer, err := newDDLWriter(gormx.DBType(*dbType), "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	tables, warnings, err := loadModelTables(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(tables) == 0 {
+		log.Fatalf("%s 中没有 gorm 模型", strings.Join(patterns, " "))
+	}
+	for _, table := range tables {
+		writer.writeTable(table)
+	}
+	for _, warning := range append(warnings, writer.warnings...) {
+		log.Printf("warning: %s", warning)
+	}
+
+	header := fmt.Sprintf("-- Code generated by jasonlabz/gentol. DO NOT EDIT.\n-- %s DDL exported from %s\n\n",
+		*dbType, strings.Join(patterns, " "))
+	writeDDLOutput(*output, header+strings.TrimSuffix(writer.String(), "\n"))
+	log.Printf("导出 %d 张表", len(tables))
+}
+
+// parseInterspersed 解析命令行参数，参数与位置参数可以交替出现，返回位置参数
+func parseInterspersed() []string {
+	var positional []string
+	args := os.Args
+	for {
+		getopt.CommandLine.Parse(args)
+		rest := getopt.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+		positional = append(positional, rest[0])
+		args = append([]string{os.Args[0]}, rest[1:]...)
+	}
+}
+
+// writeDDLOutput 写入文件，未指定文件时输出到 stdout
+func writeDDLOutput(output, content string) {
+	if output == "" {
+		os.Stdout.WriteString(content)
+		return
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("writing %s", output)
+}
+
+// modelPackage 一个目录中的 Go 源文件，按文件名顺序解析
+type modelPackage struct {
+	dir        string
+	structs    map[string]*ast.StructType
+	order      []string          // 结构体声明顺序
+	tableNames map[string]string // 结构体 -> TableName() 的返回值
+	consts     map[string]string // 字符串常量，TableName() 可能返回常量
+	embedded   map[string]bool   // 被其他结构体嵌入的结构体
+	docs       map[string]string // 结构体 -> 文档注释中的表注释
+	comments   map[*ast.Field]string
+	warnings   []string
+
+	autoIncrementDisabled map[*SchemaColumn]bool // autoIncrement:false 的主键
+}
+
+// loadModelTables 解析目录中的 gorm 模型，以 /... 结尾时包括全部子目录
+func loadModelTables(patterns []string) ([]*SchemaTable, []string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "/...")
+		if !recursive {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+					return filepath.SkipDir
+				}
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var (
+		tables   []*SchemaTable
+		warnings []string
+	)
+	for _, dir := range dirs {
+		pkg, err := parseModelPackage(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range pkg.order {
+			if table := pkg.table(name); table != nil {
+				tables = append(tables, table)
+			}
+		}
+		warnings = append(warnings, pkg.warnings...)
+	}
+	return tables, warnings, nil
+}
+
+// parseModelPackage 解析目录中的非测试 Go 文件
+func parseModelPackage(dir string) (*modelPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &modelPackage{
+		dir:        dir,
+		structs:    make(map[string]*ast.StructType),
+		tableNames: make(map[string]string),
+		consts:     make(map[string]string),
+		embedded:   make(map[string]bool),
+		docs:       make(map[string]string),
+		comments:   make(map[*ast.Field]string),
+	}
+	fset := token.NewFileSet()
+	tableNameReturns := make(map[string]ast.Expr)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				pkg.collectDecl(decl)
+			case *ast.FuncDecl:
+				if receiver, result := tableNameMethod(decl); receiver != "" {
+					tableNameReturns[receiver] = result
+				}
+			}
+		}
+		pkg.collectComments(file)
+	}
+	// 常量全部收集后再解析 TableName() 的返回值
+	for receiver, result := range tableNameReturns {
+		switch result := result.(type) {
+		case *ast.BasicLit:
+			if value, err := strconv.Unquote(result.Value); err == nil {
+				pkg.tableNames[receiver] = value
+			}
+		case *ast.Ident:
+			if value, ok := pkg.consts[result.Name]; ok {
+				pkg.tableNames[receiver] = value
+			}
+		}
+		if _, ok := pkg.tableNames[receiver]; !ok {
+			pkg.warnf("%s.TableName() does not return a string constant, using the default table name", receiver)
+		}
+	}
+	return pkg, nil
+}
+
+func (pkg *modelPackage) warnf(format string, args ...any) {
+	pkg.warnings = append(pkg.warnings, pkg.dir+": "+fmt.Sprintf(format, args...))
+}
+
+// collectDecl 收集结构体、字符串常量及结构体嵌入关系
+func (pkg *modelPackage) collectDecl(decl *ast.GenDecl) {
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok || spec.TypeParams != nil {
+				continue
+			}
+			pkg.structs[spec.Name.Name] = st
+			pkg.order = append(pkg.order, spec.Name.Name)
+			doc := spec.Doc
+			if doc == nil && len(decl.Specs) == 1 {
+				doc = decl.Doc
+			}
+			if comment := docComment(spec.Name.Name, doc); comment != "" {
+				pkg.docs[spec.Name.Name] = comment
+			}
+			for _, field := range st.Fields.List {
+				if _, embedded := schema.ParseTagSetting(gormTag(field), ";")["EMBEDDED"]; len(field.Names) == 0 || embedded {
+					pkg.embedded[strings.TrimPrefix(typeString(field.Type), "*")] = true
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range spec.Names {
+				if i < len(spec.Values) {
+					if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						pkg.consts[name.Name], _ = strconv.Unquote(lit.Value)
+					}
+				}
+			}
+		}
+	}
+}
+
+// collectComments 记录字段的行尾注释，gentol 生成的模型以 "// Comment: xxx" 保存字段注释
+func (pkg *modelPackage) collectComments(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		if field, ok := node.(*ast.Field); ok && field.Comment != nil {
+			text := strings.TrimSpace(field.Comment.Text())
+			if comment, ok := strings.CutPrefix(text, "Comment:"); ok {
+				if comment = strings.TrimSpace(comment); comment != "no comment" {
+					pkg.comments[field] = comment
+				}
+			}
+		}
+		return true
+	})
+}
+
+// docComment 文档注释中的表注释：gentol 生成的模型以 "// User 表注释" 开头，空行之后是 "struct is mapping to" 说明
+func docComment(name string, doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	paragraph, _, _ := strings.Cut(doc.Text(), "\n\n")
+	comment, ok := strings.CutPrefix(paragraph, name+" ")
+	if !ok || strings.HasPrefix(comment, "struct is mapping to") {
+		return ""
+	}
+	return strings.TrimSpace(comment)
+}
+
+// tableNameMethod 识别 func (T) TableName() string { return ... }，返回接收者类型及返回表达式
+func tableNameMethod(decl *ast.FuncDecl) (string, ast.Expr) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil {
+		return "", nil
+	}
+	receiver := strings.TrimPrefix(typeString(decl.Recv.List[0].Type), "*")
+	for _, stmt := range decl.Body.List {
+		if ret, ok := stmt.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			return receiver, ret.Results[0]
+		}
+	}
+	return receiver, nil
+}
+
+// typeString 类型表达式的源码形式，如 *time.Time、[]byte
+func typeString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return "*" + typeString(expr.X)
+	case *ast.SelectorExpr:
+		return typeString(expr.X) + "." + expr.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + typeString(expr.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(expr.Key) + "]" + typeString(expr.Value)
+	case *ast.IndexExpr:
+		return typeString(expr.X) + "[" + typeString(expr.Index) + "]"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+// modelGoTypes 常见的可空类型、gorm 类型 -> 基础 Go 类型
+var modelGoTypes = map[string]string{
+	"null.String": "string", "null.Int": "int64", "null.Int32": "int32", "null.Int16": "int16", "null.Byte": "uint8",
+	"null.Float": "float64", "null.Bool": "bool", "null.Time": "time.Time",
+	"sql.NullString": "string", "sql.NullInt64": "int64", "sql.NullInt32": "int32", "sql.NullInt16": "int16",
+	"sql.NullByte": "uint8", "sql.NullFloat64": "float64", "sql.NullBool": "bool", "sql.NullTime": "time.Time",
+	"gorm.DeletedAt": "time.Time", "byte": "uint8", "rune": "int32",
+}
+
+// modelColumnTypes 没有对应基础 Go 类型的常见类型 -> 字段类型
+var modelColumnTypes = map[string]string{
+	"datatypes.JSON": "json", "datatypes.JSONMap": "json", "datatypes.JSONSlice": "json", "json.RawMessage": "json",
+	"datatypes.Date": "date", "datatypes.Time": "time", "uuid.UUID": "uuid", "datatypes.UUID": "uuid",
+	"decimal.Decimal": "decimal", "decimal.NullDecimal": "decimal",
+}
+
+// table 将结构体转换为表结构；既没有 TableName() 也没有 gorm 标签的结构体、只用于嵌入的结构体不是模型
+func (pkg *modelPackage) table(name string) *SchemaTable {
+	tableName, hasTableName := pkg.tableNames[name]
+	if !hasTableName && (pkg.embedded[name] || !pkg.hasGormTag(pkg.structs[name], 0)) {
+		return nil
+	}
+	if !ast.IsExported(name) {
+		return nil
+	}
+	if !hasTableName {
+		tableName = schema.NamingStrategy{}.TableName(name)
+	}
+	table := &SchemaTable{Name: tableName, StructName: name, Comment: pkg.docs[name]}
+	if schemaName, shortName, found := strings.Cut(tableName, "."); found {
+		table.Schema, table.Name = schemaName, shortName
+	}
+
+	indexes := make(map[string][]modelIndexColumn)
+	pkg.addFields(table, indexes, pkg.structs[name], "", 0)
+
+	// 与 gorm 一致，未声明主键时 ID 字段为主键，整数类型的单一主键默认自增
+	if len(table.PrimaryKey) == 0 {
+		if column := findSchemaColumn(table, "id"); column != nil {
+			column.PrimaryKey = true
+			table.PrimaryKey = []string{column.Name}
+		}
+	}
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			column.Nullable = false
+			if len(table.PrimaryKey) == 1 && column.Default == nil && strings.Contains(column.GoType, "int") &&
+				!column.AutoIncrement && !pkg.autoIncrementDisabled[column] {
+				column.AutoIncrement = true
+			}
+		}
+	}
+	if len(table.PrimaryKey) > 0 {
+		table.Indexes = append(table.Indexes, &SchemaIndex{Name: "PRIMARY", Columns: table.PrimaryKey, Unique: true, PrimaryKey: true})
+	}
+	for _, indexName := range sortedKeys(indexes) {
+		entries := indexes[indexName]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].priority < entries[j].priority })
+		index := &SchemaIndex{Name: indexName}
+		for _, entry := range entries {
+			index.Columns = append(index.Columns, entry.column)
+			index.Unique = index.Unique || entry.unique
+			if entry.option != "" {
+				index.Option = entry.option
+			}
+		}
+		table.Indexes = append(table.Indexes, index)
+	}
+	return table
+}
+
+// modelIndexColumn 索引标签中的一列
+type modelIndexColumn struct {
+	column   string
+	priority int
+	unique   bool
+	option   string
+}
+
+// hasGormTag 结构体（含嵌入的结构体）是否有 gorm 标签
+func (pkg *modelPackage) hasGormTag(st *ast.StructType, depth int) bool {
+	if st == nil || depth > 5 {
+		return false
+	}
+	for _, field := range st.Fields.List {
+		if gormTag(field) != "" {
+			return true
+		}
+		if len(field.Names) == 0 && pkg.hasGormTag(pkg.structs[strings.TrimPrefix(typeString(field.Type), "*")], depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// gormTag 字段的 gorm 标签
+func gormTag(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag).Get("gorm")
+}
+
+// addFields 添加结构体的字段，嵌入的结构体及 embedded 标签的字段展开，关联字段跳过
+func (pkg *modelPackage) addFields(table *SchemaTable, indexes map[string][]modelIndexColumn, st *ast.StructType, prefix string, depth int) {
+	if st == nil || depth > 5 {
+		return
+	}
+	for _, field := range st.Fields.List {
+		tag := gormTag(field)
+		settings := schema.ParseTagSetting(tag, ";")
+		if _, ok := settings["-"]; ok {
+			if value := settings["-"]; value == "-" || value == "migration" || value == "all" {
+				continue
+			}
+		}
+		goType := typeString(field.Type)
+		baseType := strings.TrimPrefix(goType, "*")
+		_, embedded := settings["EMBEDDED"]
+		if len(field.Names) == 0 || embedded {
+			if len(field.Names) > 0 && !field.Names[0].IsExported() {
+				continue
+			}
+			switch {
+			case baseType == "gorm.Model":
+				pkg.addGormModel(table, indexes)
+			case pkg.structs[baseType] != nil:
+				pkg.addFields(table, indexes, pkg.structs[baseType], prefix+settings["EMBEDDEDPREFIX"], depth+1)
+			default:
+				pkg.warnf("%s: embedded %s cannot be resolved, skipped", table.StructName, baseType)
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() || isRelationField(pkg, goType, settings) {
+				continue
+			}
+			column := pkg.column(table, field, name.Name, goType, settings, prefix)
+			table.Columns = append(table.Columns, column)
+			if column.PrimaryKey {
+				table.PrimaryKey = append(table.PrimaryKey, column.Name)
+			}
+			addIndexTags(table, indexes, column.Name, tag)
+			if baseType == "gorm.DeletedAt" && !strings.Contains(strings.ToUpper(tag), "INDEX") {
+				addIndexTags(table, indexes, column.Name, "index")
+			}
+		}
+	}
+}
+
+// isRelationField 关联字段：声明了外键等关联标签，或类型为其他模型（及其切片）
+func isRelationField(pkg *modelPackage, goType string, settings map[string]string) bool {
+	for _, key := range []string{"FOREIGNKEY", "REFERENCES", "MANY2MANY", "POLYMORPHIC"} {
+		if _, ok := settings[key]; ok {
+			return true
+		}
+	}
+	if _, ok := settings["TYPE"]; ok {
+		return false
+	}
+	if _, ok := settings["SERIALIZER"]; ok {
+		return false
+	}
+	baseType := strings.TrimPrefix(strings.TrimPrefix(goType, "[]"), "*")
+	if strings.HasPrefix(goType, "[]") && goType != "[]byte" && goType != "[]uint8" {
+		return true
+	}
+	return pkg.structs[baseType] != nil
+}
+
+// addGormModel 展开 gorm.Model
+func (pkg *modelPackage) addGormModel(table *SchemaTable, indexes map[string][]modelIndexColumn) {
+	table.Columns = append(table.Columns,
+		&SchemaColumn{Name: "id", GoType: "uint", PrimaryKey: true, AutoIncrement: true},
+		&SchemaColumn{Name: "created_at", GoType: "time.Time", Nullable: true},
+		&SchemaColumn{Name: "updated_at", GoType: "time.Time", Nullable: true},
+		&SchemaColumn{Name: "deleted_at", GoType: "time.Time", Nullable: true},
+	)
+	table.PrimaryKey = append(table.PrimaryKey, "id")
+	addIndexTags(table, indexes, "deleted_at", "index")
+}
+
+// column 按 gorm 标签构造字段，未指定 not null 的字段与 gorm 一致允许为空
+func (pkg *modelPackage) column(table *SchemaTable, field *ast.Field, fieldName, goType string, settings map[string]string, prefix string) *SchemaColumn {
+	baseType := strings.TrimPrefix(goType, "*")
+	column := &SchemaColumn{
+		Name:     settings["COLUMN"],
+		GoType:   baseType,
+		Nullable: true,
+		Comment:  settings["COMMENT"],
+	}
+	if column.Name == "" {
+		column.Name = schema.NamingStrategy{}.ColumnName("", fieldName)
+	}
+	column.Name = prefix + column.Name
+	if column.Comment == "" {
+		column.Comment = pkg.comments[field]
+	}
+	if goType, ok := modelGoTypes[baseType]; ok {
+		column.GoType = goType
+	}
+	if columnType, ok := modelColumnTypes[baseType]; ok {
+		column.ColumnType = columnType
+	}
+	if columnType := settings["TYPE"]; columnType != "" {
+		column.ColumnType = columnType
+	}
+	if _, ok := goKinds[column.GoType]; !ok && column.GoType != "string" && column.ColumnType == "" {
+		if _, ok := settings["SERIALIZER"]; ok {
+			column.ColumnType = "text"
+		}
+	}
+	column.Length = tagInt(settings["SIZE"])
+	column.Precision = tagInt(settings["PRECISION"])
+	column.Scale = tagInt(settings["SCALE"])
+	if _, ok := settings["NOT NULL"]; ok {
+		column.Nullable = false
+	}
+	if _, ok := settings["NOTNULL"]; ok {
+		column.Nullable = false
+	}
+	_, column.PrimaryKey = settings["PRIMARYKEY"]
+	if _, ok := settings["PRIMARY_KEY"]; ok {
+		column.PrimaryKey = true
+	}
+	if value, ok := settings["AUTOINCREMENT"]; ok {
+		column.AutoIncrement = !strings.EqualFold(value, "false")
+		if !column.AutoIncrement {
+			if pkg.autoIncrementDisabled == nil {
+				pkg.autoIncrementDisabled = make(map[*SchemaColumn]bool)
+			}
+			pkg.autoIncrementDisabled[column] = true
+		}
+	}
+	if _, ok := settings["UNIQUE"]; ok {
+		column.Unique = true
+	}
+	if value, ok := settings["DEFAULT"]; ok {
+		column.Default = &value
+	}
+	return column
+}
+
+// tagInt 标签中的整数值
+func tagInt(value string) int64 {
+	result, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return result
+}
+
+// addIndexTags 解析 index、uniqueIndex 标签：index:name,unique,class:FULLTEXT,priority:2；同名索引合并为联合索引
+func addIndexTags(table *SchemaTable, indexes map[string][]modelIndexColumn, column, tag string) {
+	for _, item := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(item, ":")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if key != "INDEX" && key != "UNIQUEINDEX" {
+			continue
+		}
+		entry := modelIndexColumn{column: column, priority: 10, unique: key == "UNIQUEINDEX"}
+		parts := strings.Split(value, ",")
+		name := strings.TrimSpace(parts[0])
+		for _, part := range parts[1:] {
+			option, optionValue, _ := strings.Cut(strings.TrimSpace(part), ":")
+			switch strings.ToUpper(option) {
+			case "UNIQUE":
+				entry.unique = true
+			case "PRIORITY":
+				entry.priority = int(tagInt(optionValue))
+			case "CLASS":
+				switch strings.ToUpper(optionValue) {
+				case "UNIQUE":
+					entry.unique = true
+				case "FULLTEXT", "SPATIAL":
+					entry.option = strings.ToUpper(optionValue)
+				}
+			}
+		}
+		if name == "" {
+			name = "idx_" + strings.ReplaceAll(buildFullTableName(table.Schema, table.Name), ".", "_") + "_" + column
+		}
+		if !slices.ContainsFunc(indexes[name], func(c modelIndexColumn) bool { return c.column == column }) {
+			indexes[name] = append(indexes[name], entry)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// exportFixture gentol 生成风格的模型：文档注释保存表注释，行尾注释保存字段注释
+const exportFixture = `package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User 用户表
+// 第二行
+//
+// User struct is mapping to the users table
+type User struct {
+	ID        int64     ` + "`gorm:\"primaryKey;column:id\"`" + `
+	Status    string    ` + "`gorm:\"column:status;type:enum('a','b');default:a\"`" + `
+	Name      string    ` + "`gorm:\"column:name;size:64;not null;default:'';uniqueIndex\"`" + ` // Comment: 名称
+	Code      string    ` + "`gorm:\"column:code;size:32;default:gen_code()\"`" + `
+	Count     int       ` + "`gorm:\"column:count;default:0\"`" + `
+	CreatedAt time.Time ` + "`gorm:\"column:created_at;default:CURRENT_TIMESTAMP;index:idx_created\"`" + `
+}
+
+func (User) TableName() string {
+	return tableUsers
+}
+
+const tableUsers = "users"
+
+type (
+	// Order 订单
+	Order struct {
+		gorm.Model
+		UserID int64  ` + "`gorm:\"index:idx_user_no,priority:1\"`" + `
+		No     string ` + "`gorm:\"size:20;index:idx_user_no,priority:2\"`" + `
+		User   *User
+	}
+)
+
+// Item struct is mapping to the items table
+type Item struct {
+	ID     uint ` + "`gorm:\"primaryKey;autoIncrement:false\"`" + `
+	Hidden int  ` + "`gorm:\"-\"`" + `
+}
+
+type helper struct {
+	A int ` + "`gorm:\"column:a\"`" + `
+}
+`
+
+func exportDDL(t *testing.T, dbType gormx.DBType, dir string) (string, []string) {
+	t.Helper()
+	tables, warnings, err := loadModelTables([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := newDDLWriter(dbType, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		writer.writeTable(table)
+	}
+	return writer.String(), append(warnings, writer.warnings...)
+}
+
+func TestDDLExport(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "model.go"), exportFixture)
+	writeTestFile(t, filepath.Join(dir, "model_test.go"), "package model\n\ntype Ignored struct {\n\tID int `gorm:\"primaryKey\"`\n}\n")
+
+	tests := []struct {
+		dbType gormx.DBType
+		want   string
+	}{
+		{gormx.DBTypeMySQL, `CREATE TABLE IF NOT EXISTS users (
+    id         BIGINT        NOT NULL AUTO_INCREMENT,
+    status     enum('a','b') DEFAULT 'a',
+    name       VARCHAR(64)   DEFAULT '' NOT NULL COMMENT '名称',
+    code       VARCHAR(32)   DEFAULT gen_code(),
+    count      BIGINT        DEFAULT 0,
+    created_at DATETIME      DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_created (created_at),
+    UNIQUE KEY idx_users_name (name)
+) COMMENT = '用户表
+第二行';
+
+CREATE TABLE IF NOT EXISTS orders (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    user_id    BIGINT,
+    no         VARCHAR(20),
+    PRIMARY KEY (id),
+    KEY idx_orders_deleted_at (deleted_at),
+    KEY idx_user_no (user_id, no)
+) COMMENT = '订单';
+
+CREATE TABLE IF NOT EXISTS items (
+    id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (id)
+);
+`},
+		{gormx.DBTypePostgres, `CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL     NOT NULL,
+    status     enum('a','b') DEFAULT 'a',
+    name       VARCHAR(64)   DEFAULT '' NOT NULL,
+    code       VARCHAR(32)   DEFAULT gen_code(),
+    count      BIGINT        DEFAULT 0,
+    created_at TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_created ON users (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_name ON users (name);
+COMMENT ON TABLE users IS '用户表
+第二行';
+COMMENT ON COLUMN users.name IS '名称';
+
+CREATE TABLE IF NOT EXISTS orders (
+    id         BIGSERIAL   NOT NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    user_id    BIGINT,
+    no         VARCHAR(20),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_no ON orders (user_id, no);
+COMMENT ON TABLE orders IS '订单';
+
+CREATE TABLE IF NOT EXISTS items (
+    id BIGINT NOT NULL,
+    PRIMARY KEY (id)
+);
+`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dbType), func(t *testing.T) {
+			got, warnings := exportDDL(t, tt.dbType, dir)
+			if got = strings.TrimSuffix(got, "\n"); got != tt.want {
+				t.Errorf("export DDL =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], "enum('a','b')") {
+				t.Errorf("warnings = %q, want the enum type warning only", warnings)
+			}
+		})
+	}
+}
+
+func TestDocComment(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"// User 用户表\n//\n// User struct is mapping to the users table\n", "用户表"},
+		{"// User 用户表\n// 第二行\n", "用户表\n第二行"},
+		{"// User struct is mapping to the users table\n", ""},
+		{"// 用户表\n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		src := "package model\n\n" + tt.doc + "type User struct {\n\tID int `gorm:\"primaryKey\"`\n}\n"
+		dir := t.TempDir()
+		writeTestFile(t, filepath.Join(dir, "user.go"), src)
+		pkg, err := parseModelPackage(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pkg.docs["User"]; got != tt.want {
+			t.Errorf("doc %q: table comment = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
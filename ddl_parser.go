@@ -0,0 +1,1124 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// ddlParser 解析 CREATE TABLE、ALTER TABLE、CREATE INDEX、COMMENT ON 等 DDL 语句，得到与在线读取相同结构的表
+type ddlParser struct {
+	dbType        gormx.DBType
+	defaultSchema string                  // 未指定 schema 的表所属 schema
+	tables        map[string]*SchemaTable // 完整表名 -> 表结构
+	order         []string                // 建表顺序
+	warnings      []string                // 无法识别的语句、子句
+	source        string                  // 当前解析的文件
+	translate     bool                    // 翻译模式下同时记录被忽略的外键、检查约束、表选项等
+}
+
+// newDDLParser 目前支持 MySQL、PostgreSQL（含 Greenplum）与达梦语法
+func newDDLParser(dbType gormx.DBType) (*ddlParser, error) {
+	parser := &ddlParser{dbType: dbType, tables: make(map[string]*SchemaTable)}
+	switch dbType {
+	case gormx.DBTypeMySQL, gormx.DBTypeDM:
+	case gormx.DBTypePostgres, gormx.DBTypeGreenplum:
+		parser.defaultSchema = "public"
+	default:
+		return nil, fmt.Errorf("parsing DDL of %s is not supported, use mysql, postgres or dm", dbType)
+	}
+	return parser, nil
+}
+
+// sqlStmt 一条语句（或其中的一段）的词法单元及读取位置
+type sqlStmt struct {
+	tokens []sqlToken
+	pos    int
+	index  int // 语句在文件中的序号
+}
+
+func (s *sqlStmt) eof() bool {
+	return s.pos >= len(s.tokens)
+}
+
+// peek 查看当前位置之后第 offset 个词法单元，越界时返回空词法单元
+func (s *sqlStmt) peek(offset int) sqlToken {
+	if s.pos+offset >= len(s.tokens) {
+		return sqlToken{kind: tokPunct}
+	}
+	return s.tokens[s.pos+offset]
+}
+
+func (s *sqlStmt) next() sqlToken {
+	token := s.peek(0)
+	s.pos++
+	return token
+}
+
+// accept 后续词法单元依次与 words（关键字或符号）匹配时读取并返回 true
+func (s *sqlStmt) accept(words ...string) bool {
+	for i, word := range words {
+		token := s.peek(i)
+		if !token.is(word) && !(token.kind == tokPunct && token.raw == word) {
+			return false
+		}
+	}
+	s.pos += len(words)
+	return true
+}
+
+// isAny 当前词法单元是否为 words 中的某个关键字
+func (s *sqlStmt) isAny(words ...string) bool {
+	token := s.peek(0)
+	for _, word := range words {
+		if token.is(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// take 读取一个词法单元；位于左括号时读取到匹配的右括号（含括号）
+func (s *sqlStmt) take() []sqlToken {
+	start := s.pos
+	if s.peek(0).raw != "(" {
+		s.pos++
+		return s.tokens[start:min(s.pos, len(s.tokens))]
+	}
+	depth := 0
+	for !s.eof() {
+		switch s.next().raw {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	return s.tokens[start:s.pos]
+}
+
+// group 读取括号内的词法单元，不含括号；当前不是左括号时返回 nil
+func (s *sqlStmt) group() []sqlToken {
+	if s.peek(0).raw != "(" {
+		return nil
+	}
+	tokens := s.take()
+	if len(tokens) < 2 || tokens[len(tokens)-1].raw != ")" {
+		return tokens[1:]
+	}
+	return tokens[1 : len(tokens)-1]
+}
+
+// rest 读取剩余的词法单元
+func (s *sqlStmt) rest() []sqlToken {
+	tokens := s.tokens[min(s.pos, len(s.tokens)):]
+	s.pos = len(s.tokens)
+	return tokens
+}
+
+// splitTopLevel 按括号外的逗号拆分
+func splitTopLevel(tokens []sqlToken) [][]sqlToken {
+	var (
+		parts [][]sqlToken
+		depth int
+		start int
+	)
+	for i, token := range tokens {
+		switch {
+		case token.raw == "(":
+			depth++
+		case token.raw == ")":
+			depth--
+		case token.raw == "," && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
+
+// warn 记录无法识别的内容，生成时打印、翻译时汇总报告
+func (p *ddlParser) warn(s *sqlStmt, format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf("%s: statement %d: %s", p.source, s.index, fmt.Sprintf(format, args...)))
+}
+
+// skip 翻译模式下记录被忽略的内容
+func (p *ddlParser) skip(s *sqlStmt, format string, args ...any) {
+	if p.translate {
+		p.warn(s, format, args...)
+	}
+}
+
+// parse 依次解析 content 中的语句，source 为文件名
+func (p *ddlParser) parse(source, content string) {
+	p.source = source
+	for i, tokens := range splitStatements(content, p.dbType) {
+		p.parseStatement(&sqlStmt{tokens: tokens, index: i + 1})
+	}
+}
+
+// parseStatement 解析一条语句，与表结构无关的语句（INSERT、CREATE SEQUENCE 等）直接忽略
+func (p *ddlParser) parseStatement(s *sqlStmt) {
+	switch {
+	case s.accept("CREATE"):
+		s.accept("OR", "REPLACE")
+		for s.accept("TEMPORARY") || s.accept("TEMP") || s.accept("UNLOGGED") || s.accept("GLOBAL") || s.accept("LOCAL") {
+		}
+		switch {
+		case s.accept("TABLE"):
+			p.createTable(s)
+		case s.isAny("UNIQUE", "FULLTEXT", "SPATIAL", "INDEX"):
+			p.createIndex(s)
+		default:
+			p.skip(s, "statement %s is skipped", joinTokens(s.tokens[:min(3, len(s.tokens))]))
+		}
+	case s.accept("ALTER", "TABLE"):
+		p.alterTable(s)
+	case s.accept("DROP", "TABLE"):
+		p.dropTable(s)
+	case s.accept("DROP", "INDEX"):
+		p.dropIndex(s)
+	case s.accept("COMMENT", "ON"):
+		p.commentOn(s)
+	case s.isAny("SET", "USE", "BEGIN", "COMMIT", "START"):
+	default:
+		p.skip(s, "statement %s is skipped", joinTokens(s.tokens[:min(3, len(s.tokens))]))
+	}
+}
+
+// ident 读取标识符，PostgreSQL 未加引号的标识符转为小写，达梦转为大写
+func (p *ddlParser) ident(s *sqlStmt) string {
+	return p.fold(s.next())
+}
+
+// fold 按数据库规则转换未加引号的标识符
+func (p *ddlParser) fold(token sqlToken) string {
+	switch {
+	case token.kind != tokWord || p.dbType == gormx.DBTypeMySQL:
+		return token.text
+	case p.dbType == gormx.DBTypeDM:
+		return strings.ToUpper(token.text)
+	}
+	return strings.ToLower(token.text)
+}
+
+// nameParts 读取以点号分隔的名称
+func (p *ddlParser) nameParts(s *sqlStmt) []string {
+	parts := []string{p.ident(s)}
+	for s.accept(".") {
+		parts = append(parts, p.ident(s))
+	}
+	return parts
+}
+
+// qualifiedName 读取 [schema.]name，未指定 schema 时使用默认 schema
+func (p *ddlParser) qualifiedName(s *sqlStmt) (string, string) {
+	parts := p.nameParts(s)
+	if len(parts) == 1 {
+		return p.defaultSchema, parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// lookupTable 查找已定义的表，不存在时记录警告
+func (p *ddlParser) lookupTable(s *sqlStmt, schema, name string) *SchemaTable {
+	table, ok := p.tables[buildFullTableName(schema, name)]
+	if !ok {
+		p.warn(s, "table %s is not defined", buildFullTableName(schema, name))
+	}
+	return table
+}
+
+// tableRef 读取表名并查找已定义的表
+func (p *ddlParser) tableRef(s *sqlStmt) *SchemaTable {
+	schema, name := p.qualifiedName(s)
+	return p.lookupTable(s, schema, name)
+}
+
+// putTable 保存表结构，重复定义的表保留原有顺序
+func (p *ddlParser) putTable(table *SchemaTable) {
+	key := buildFullTableName(table.Schema, table.Name)
+	if _, ok := p.tables[key]; !ok {
+		p.order = append(p.order, key)
+	}
+	p.tables[key] = table
+}
+
+// createTable CREATE TABLE [IF NOT EXISTS] name (...) | LIKE other | PARTITION OF parent
+func (p *ddlParser) createTable(s *sqlStmt) {
+	ifNotExists := s.accept("IF", "NOT", "EXISTS")
+	schema, name := p.qualifiedName(s)
+	if _, exists := p.tables[buildFullTableName(schema, name)]; exists && ifNotExists {
+		return
+	}
+	table := &SchemaTable{Schema: schema, Name: name}
+
+	switch {
+	case s.accept("LIKE"), s.accept("PARTITION", "OF"):
+		if source := p.tableRef(s); source != nil {
+			p.copyTable(table, source)
+		}
+	case s.peek(0).raw == "(":
+		for _, element := range splitTopLevel(s.group()) {
+			p.tableElement(table, &sqlStmt{tokens: element, index: s.index})
+		}
+		p.tableOptions(table, s)
+	default:
+		p.warn(s, "CREATE TABLE %s without column definitions is not supported", name)
+		return
+	}
+	p.putTable(table)
+}
+
+// copyTable 复制字段、主键及索引，用于 LIKE 与分区子表
+func (p *ddlParser) copyTable(table, source *SchemaTable) {
+	for _, column := range source.Columns {
+		copied := *column
+		table.Columns = append(table.Columns, &copied)
+	}
+	table.PrimaryKey = slices.Clone(source.PrimaryKey)
+	for _, index := range source.Indexes {
+		copied := *index
+		table.Indexes = append(table.Indexes, &copied)
+	}
+}
+
+// tableElement 建表语句括号内的一项：字段、约束或索引
+func (p *ddlParser) tableElement(table *SchemaTable, e *sqlStmt) {
+	switch {
+	case e.accept("CONSTRAINT"):
+		p.tableConstraint(table, e, p.ident(e))
+	case e.isAny("PRIMARY", "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "FOREIGN", "CHECK", "EXCLUDE", "CLUSTER"),
+		e.peek(0).is("NOT") && e.peek(1).is("CLUSTER"):
+		p.tableConstraint(table, e, "")
+	case e.accept("LIKE"):
+		if source := p.tableRef(e); source != nil {
+			p.copyTable(table, source)
+		}
+	default:
+		p.addColumn(table, e)
+	}
+}
+
+// storageOptions 只影响存储的表选项，翻译时不作报告
+var storageOptions = []string{
+	"ENGINE", "CHARSET", "CHARACTER", "COLLATE", "AUTO_INCREMENT", "ROW_FORMAT", "STORAGE", "WITH",
+}
+
+// tableOptions 建表语句括号后的表选项，目前只读取 MySQL 的表注释
+func (p *ddlParser) tableOptions(table *SchemaTable, s *sqlStmt) {
+	var skipped []sqlToken
+	for !s.eof() {
+		switch {
+		case s.accept("COMMENT"):
+			s.accept("=")
+			table.Comment = s.next().text
+		case s.accept("DEFAULT"), s.accept(","):
+		case s.isAny(storageOptions...):
+			s.next()
+			s.accept("SET")
+			s.accept("=")
+			s.take()
+		default:
+			skipped = append(skipped, s.take()...)
+		}
+	}
+	if len(skipped) > 0 {
+		p.skip(s, "table %s: table options %s are dropped", table.Name, joinTokens(skipped))
+	}
+}
+
+// tableConstraint 主键、唯一约束及索引；外键、检查约束与生成代码无关，直接忽略
+func (p *ddlParser) tableConstraint(table *SchemaTable, e *sqlStmt, name string) {
+	// 达梦的 [NOT] CLUSTER PRIMARY KEY、CLUSTER UNIQUE KEY
+	e.accept("NOT", "CLUSTER")
+	e.accept("CLUSTER")
+	switch {
+	case e.accept("PRIMARY", "KEY"):
+		p.skipIndexType(e)
+		if columns := p.indexColumns(table, e); columns != nil {
+			p.setPrimaryKey(table, name, columns)
+		}
+	case e.accept("UNIQUE"):
+		_ = e.accept("KEY") || e.accept("INDEX")
+		name = p.indexName(e, name)
+		p.skipIndexType(e)
+		columns := p.indexColumns(table, e)
+		if columns == nil {
+			return
+		}
+		p.addIndex(table, name, columns, true, false, "")
+		if len(columns) == 1 {
+			if column := findSchemaColumn(table, columns[0]); column != nil {
+				column.Unique = true
+			}
+		}
+	case e.accept("KEY"), e.accept("INDEX"):
+		name = p.indexName(e, name)
+		p.skipIndexType(e)
+		if columns := p.indexColumns(table, e); columns != nil {
+			p.addIndex(table, name, columns, false, false, "")
+		}
+	case e.isAny("FULLTEXT", "SPATIAL"):
+		option := strings.ToUpper(e.next().text)
+		_ = e.accept("KEY") || e.accept("INDEX")
+		name = p.indexName(e, name)
+		if columns := p.indexColumns(table, e); columns != nil {
+			p.addIndex(table, name, columns, false, false, option)
+		}
+	case e.isAny("FOREIGN", "CHECK", "EXCLUDE"):
+		p.skip(e, "table %s: constraint %s is dropped", table.Name, joinTokens(e.rest()))
+	default:
+		p.warn(e, "table %s: unsupported constraint %s", table.Name, joinTokens(e.rest()))
+	}
+}
+
+// indexName 读取可选的索引名
+func (p *ddlParser) indexName(e *sqlStmt, name string) string {
+	if token := e.peek(0); token.raw != "(" && !token.is("USING") {
+		return p.ident(e)
+	}
+	return name
+}
+
+// skipIndexType 跳过 USING BTREE 等索引类型
+func (p *ddlParser) skipIndexType(e *sqlStmt) {
+	if e.accept("USING") {
+		e.next()
+	}
+}
+
+// indexColumns 读取索引列，忽略前缀长度与排序；表达式索引无法对应到字段，返回 nil
+func (p *ddlParser) indexColumns(table *SchemaTable, e *sqlStmt) []string {
+	group := e.group()
+	if len(group) == 0 {
+		p.warn(e, "table %s: missing index columns", table.Name)
+		return nil
+	}
+	columns := make([]string, 0)
+	for _, part := range splitTopLevel(group) {
+		item := &sqlStmt{tokens: part}
+		if first := item.peek(0); first.raw == "(" ||
+			(p.dbType != gormx.DBTypeMySQL && first.kind == tokWord && item.peek(1).raw == "(") {
+			p.warn(e, "table %s: expression index (%s) is not supported", table.Name, joinTokens(group))
+			return nil
+		}
+		columns = append(columns, p.ident(item))
+	}
+	return columns
+}
+
+// defaultIndexName 未命名索引按数据库的规则命名
+func (p *ddlParser) defaultIndexName(table *SchemaTable, columns []string, unique, primaryKey bool) string {
+	if p.dbType == gormx.DBTypeMySQL {
+		if primaryKey {
+			return "PRIMARY"
+		}
+		return columns[0]
+	}
+	switch {
+	case primaryKey:
+		return table.Name + "_pkey"
+	case unique:
+		return table.Name + "_" + strings.Join(columns, "_") + "_key"
+	}
+	return table.Name + "_" + strings.Join(columns, "_") + "_idx"
+}
+
+// addIndex 添加索引，同名索引被替换
+func (p *ddlParser) addIndex(table *SchemaTable, name string, columns []string, unique, primaryKey bool, option string) {
+	if name == "" {
+		name = p.defaultIndexName(table, columns, unique, primaryKey)
+	}
+	table.Indexes = slices.DeleteFunc(table.Indexes, func(index *SchemaIndex) bool { return index.Name == name })
+	table.Indexes = append(table.Indexes, &SchemaIndex{
+		Name:       name,
+		Columns:    columns,
+		Unique:     unique,
+		PrimaryKey: primaryKey,
+		Option:     option,
+	})
+}
+
+// setPrimaryKey 设置主键，主键字段不可为空
+func (p *ddlParser) setPrimaryKey(table *SchemaTable, name string, columns []string) {
+	p.dropPrimaryKey(table)
+	table.PrimaryKey = columns
+	for _, name := range columns {
+		if column := findSchemaColumn(table, name); column != nil {
+			column.PrimaryKey = true
+			column.Nullable = false
+		}
+	}
+	p.addIndex(table, name, columns, true, true, "")
+}
+
+// dropPrimaryKey 删除主键
+func (p *ddlParser) dropPrimaryKey(table *SchemaTable) {
+	for _, column := range table.Columns {
+		column.PrimaryKey = false
+	}
+	table.PrimaryKey = nil
+	table.Indexes = slices.DeleteFunc(table.Indexes, func(index *SchemaIndex) bool { return index.PrimaryKey })
+}
+
+// dropIndexByName 删除索引或约束，主键约束同时清除字段的主键标记
+func (p *ddlParser) dropIndexByName(table *SchemaTable, name string) bool {
+	for _, index := range table.Indexes {
+		if index.Name != name {
+			continue
+		}
+		if index.PrimaryKey {
+			p.dropPrimaryKey(table)
+		}
+		table.Indexes = slices.DeleteFunc(table.Indexes, func(index *SchemaIndex) bool { return index.Name == name })
+		return true
+	}
+	return false
+}
+
+// findSchemaColumn 按名称查找字段
+func findSchemaColumn(table *SchemaTable, name string) *SchemaColumn {
+	for _, column := range table.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column
+		}
+	}
+	return nil
+}
+
+// columnStopWords 字段类型之后的约束关键字
+var columnStopWords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true, "KEY": true,
+	"AUTO_INCREMENT": true, "AUTOINCREMENT": true, "COMMENT": true, "REFERENCES": true, "CHECK": true,
+	"CONSTRAINT": true, "COLLATE": true, "CHARSET": true, "ON": true, "AS": true, "GENERATED": true,
+	"IDENTITY": true, "VISIBLE": true, "INVISIBLE": true, "COLUMN_FORMAT": true, "STORAGE": true,
+	"SRID": true, "FIRST": true, "AFTER": true, "CLUSTER": true,
+}
+
+// atColumnConstraint 当前位置是否为字段约束
+func atColumnConstraint(e *sqlStmt) bool {
+	token := e.peek(0)
+	if token.kind != tokWord {
+		return false
+	}
+	return columnStopWords[strings.ToUpper(token.text)] || (token.is("CHARACTER") && e.peek(1).is("SET"))
+}
+
+// addColumn 解析字段定义并添加到表中，MySQL 的 FIRST/AFTER 决定插入位置
+func (p *ddlParser) addColumn(table *SchemaTable, e *sqlStmt) {
+	column, primaryKey, unique, after := p.columnDefinition(table, e)
+	if column == nil {
+		return
+	}
+	table.Columns = slices.DeleteFunc(table.Columns, func(c *SchemaColumn) bool { return strings.EqualFold(c.Name, column.Name) })
+	position := len(table.Columns)
+	if after != nil {
+		position = 0
+		if index := slices.IndexFunc(table.Columns, func(c *SchemaColumn) bool { return strings.EqualFold(c.Name, *after) }); index >= 0 {
+			position = index + 1
+		}
+	}
+	table.Columns = slices.Insert(table.Columns, position, column)
+	p.columnConstraints(table, column, primaryKey, unique)
+}
+
+// columnConstraints 字段级主键、唯一约束同时生成索引
+func (p *ddlParser) columnConstraints(table *SchemaTable, column *SchemaColumn, primaryKey, unique bool) {
+	if primaryKey {
+		p.setPrimaryKey(table, "", []string{column.Name})
+	}
+	// MySQL 的 serial 类型自带唯一约束
+	if unique || column.Unique {
+		column.Unique = true
+		p.addIndex(table, "", []string{column.Name}, true, false, "")
+	}
+}
+
+// columnDefinition 解析 name type [constraints]，after 为 AFTER 指定的字段，FIRST 时为空串，未指定时为 nil
+func (p *ddlParser) columnDefinition(table *SchemaTable, e *sqlStmt) (column *SchemaColumn, primaryKey, unique bool, after *string) {
+	if e.eof() {
+		return nil, false, false, nil
+	}
+	column = &SchemaColumn{Name: p.ident(e), Nullable: true}
+	var typeTokens []sqlToken
+	for !e.eof() && !atColumnConstraint(e) {
+		typeTokens = append(typeTokens, e.take()...)
+	}
+	if len(typeTokens) == 0 {
+		p.warn(e, "column %s.%s has no type", table.Name, column.Name)
+		return nil, false, false, nil
+	}
+	p.applyType(column, typeTokens)
+
+	for !e.eof() {
+		switch {
+		case e.accept("NOT", "NULL"):
+			column.Nullable = false
+		case e.accept("NULL"):
+			column.Nullable = true
+		case e.accept("DEFAULT"):
+			if !e.accept("NULL") {
+				p.applyDefault(column, p.expression(e))
+			}
+		case e.accept("NOT", "CLUSTER"), e.accept("CLUSTER"):
+			// 达梦的 [NOT] CLUSTER PRIMARY KEY
+		case e.accept("PRIMARY", "KEY"), e.accept("PRIMARY"), e.accept("KEY"):
+			primaryKey = true
+			column.Nullable = false
+		case e.accept("UNIQUE"):
+			e.accept("KEY")
+			unique = true
+		case e.accept("AUTO_INCREMENT"), e.accept("AUTOINCREMENT"):
+			column.AutoIncrement = true
+		case e.accept("COMMENT"):
+			column.Comment = e.next().text
+		case e.accept("CONSTRAINT"):
+			e.next()
+		case e.accept("REFERENCES"):
+			p.skip(e, "column %s.%s: foreign key is dropped", table.Name, column.Name)
+			p.skipReferences(e)
+		case e.accept("CHECK"):
+			p.skip(e, "column %s.%s: CHECK %s is dropped", table.Name, column.Name, joinTokens(e.take()))
+		case e.accept("COLLATE"), e.accept("CHARSET"), e.accept("CHARACTER", "SET"),
+			e.accept("COLUMN_FORMAT"), e.accept("STORAGE"), e.accept("SRID"):
+			e.next()
+		case e.accept("ON", "UPDATE"):
+			p.skip(e, "column %s.%s: ON UPDATE %s is dropped", table.Name, column.Name, joinTokens(p.expression(e)))
+		case e.accept("GENERATED"):
+			_ = e.accept("ALWAYS") || e.accept("BY", "DEFAULT")
+			e.accept("AS")
+			if e.accept("IDENTITY") {
+				column.AutoIncrement = true
+				column.Nullable = false
+				e.group()
+				continue
+			}
+			e.take()
+			column.ReadOnly = true
+		case e.accept("AS"):
+			e.take()
+			column.ReadOnly = true
+		case e.accept("IDENTITY"):
+			column.AutoIncrement = true
+			e.group()
+		case e.accept("VIRTUAL"), e.accept("STORED"), e.accept("PERSISTENT"), e.accept("VISIBLE"), e.accept("INVISIBLE"):
+		case e.accept("FIRST"):
+			after = new(string)
+		case e.accept("AFTER"):
+			name := p.ident(e)
+			after = &name
+		default:
+			p.warn(e, "column %s.%s: unsupported clause %s", table.Name, column.Name, joinTokens(e.rest()))
+		}
+	}
+	return column, primaryKey, unique, after
+}
+
+// expression 读取表达式，直到下一个字段约束
+func (p *ddlParser) expression(e *sqlStmt) []sqlToken {
+	var tokens []sqlToken
+	for !e.eof() && !atColumnConstraint(e) {
+		tokens = append(tokens, e.take()...)
+	}
+	return tokens
+}
+
+// skipReferences 跳过外键引用：REFERENCES t [(cols)] [MATCH ...] [ON DELETE|UPDATE action] [DEFERRABLE ...]
+func (p *ddlParser) skipReferences(e *sqlStmt) {
+	p.nameParts(e)
+	e.group()
+	for {
+		switch {
+		case e.accept("MATCH"):
+			e.next()
+		case e.accept("ON", "DELETE"), e.accept("ON", "UPDATE"):
+			switch {
+			case e.accept("NO", "ACTION"), e.accept("SET", "NULL"), e.accept("SET", "DEFAULT"):
+			default:
+				e.next()
+			}
+		case e.accept("NOT", "DEFERRABLE"), e.accept("DEFERRABLE"),
+			e.accept("INITIALLY", "DEFERRED"), e.accept("INITIALLY", "IMMEDIATE"):
+		default:
+			return
+		}
+	}
+}
+
+// applyDefault 记录默认值：MySQL 与 information_schema 一致去掉字符串引号，PostgreSQL 保留表达式原文
+func (p *ddlParser) applyDefault(column *SchemaColumn, tokens []sqlToken) {
+	if len(tokens) == 0 {
+		return
+	}
+	value := joinTokens(tokens)
+	if p.dbType == gormx.DBTypeMySQL && len(tokens) == 1 && tokens[0].kind == tokString && !isBitString(tokens[0]) {
+		value = tokens[0].text
+	}
+	if strings.HasPrefix(strings.ToLower(value), "nextval(") {
+		column.AutoIncrement = true
+	}
+	column.Default = &value
+}
+
+// sqlType 拆分后的字段类型
+type sqlType struct {
+	name   string   // 类型名，如 varchar、double precision、timestamp with time zone
+	params []int64  // 括号中的数字参数
+	args   string   // 括号及其中的内容原文，如 (10,2)、('a','b')
+	mods   []string // unsigned、zerofill 等修饰
+	array  bool
+}
+
+// splitType 拆分字段类型的词法单元
+func splitType(tokens []sqlToken) sqlType {
+	var (
+		result  sqlType
+		words   []string
+		grouped bool
+	)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.raw == "(" && !grouped:
+			start := i
+			for i < len(tokens) && tokens[i].raw != ")" {
+				if tokens[i].kind == tokNumber {
+					value, _ := strconv.ParseInt(tokens[i].text, 10, 64)
+					result.params = append(result.params, value)
+				}
+				i++
+			}
+			result.args = joinTokens(tokens[start:min(i+1, len(tokens))])
+			grouped = true
+		case token.raw == "[":
+			result.array = true
+			for i < len(tokens) && tokens[i].raw != "]" {
+				i++
+			}
+		case token.is("ARRAY"):
+			result.array = true
+		case token.is("UNSIGNED"), token.is("SIGNED"), token.is("ZEROFILL"):
+			result.mods = append(result.mods, strings.ToLower(token.text))
+		case token.kind == tokWord:
+			words = append(words, strings.ToLower(token.text))
+		}
+	}
+	result.name = strings.Join(words, " ")
+	return result
+}
+
+// mysqlTypeAliases MySQL 类型别名 -> information_schema 中的 DATA_TYPE
+var mysqlTypeAliases = map[string]string{
+	"integer": "int", "int4": "int", "int8": "bigint", "int2": "smallint", "int1": "tinyint",
+	"numeric": "decimal", "dec": "decimal", "fixed": "decimal", "real": "double", "double precision": "double",
+	"character": "char", "character varying": "varchar", "bool": "tinyint", "boolean": "tinyint",
+	"serial": "bigint",
+}
+
+// postgresTypes PostgreSQL 类型别名 -> [udt_name, 规范名称]
+var postgresTypes = map[string][2]string{
+	"int": {"int4", "integer"}, "integer": {"int4", "integer"}, "int4": {"int4", "integer"},
+	"serial": {"int4", "integer"}, "serial4": {"int4", "integer"},
+	"bigint": {"int8", "bigint"}, "int8": {"int8", "bigint"}, "bigserial": {"int8", "bigint"}, "serial8": {"int8", "bigint"},
+	"smallint": {"int2", "smallint"}, "int2": {"int2", "smallint"}, "smallserial": {"int2", "smallint"}, "serial2": {"int2", "smallint"},
+	"bool": {"bool", "boolean"}, "boolean": {"bool", "boolean"},
+	"real": {"float4", "real"}, "float4": {"float4", "real"},
+	"double precision": {"float8", "double precision"}, "float8": {"float8", "double precision"}, "float": {"float8", "double precision"},
+	"numeric": {"numeric", "numeric"}, "decimal": {"numeric", "numeric"},
+	"varchar": {"varchar", "character varying"}, "character varying": {"varchar", "character varying"},
+	"char": {"bpchar", "character"}, "character": {"bpchar", "character"}, "bpchar": {"bpchar", "character"},
+	"timestamp": {"timestamp", "timestamp without time zone"}, "timestamp without time zone": {"timestamp", "timestamp without time zone"},
+	"timestamptz": {"timestamptz", "timestamp with time zone"}, "timestamp with time zone": {"timestamptz", "timestamp with time zone"},
+	"time": {"time", "time without time zone"}, "time without time zone": {"time", "time without time zone"},
+	"timetz": {"timetz", "time with time zone"}, "time with time zone": {"timetz", "time with time zone"},
+}
+
+// applyType 按数据库规则填充字段的原始类型、类型名、长度及精度
+func (p *ddlParser) applyType(column *SchemaColumn, tokens []sqlToken) {
+	typ := splitType(tokens)
+	switch p.dbType {
+	case gormx.DBTypeMySQL:
+		p.applyMySQLType(column, typ)
+	case gormx.DBTypeDM:
+		p.applyDMType(column, typ)
+	default:
+		p.applyPostgresType(column, typ)
+	}
+	switch column.DatabaseType {
+	case "char", "varchar", "binary", "varbinary", "bpchar", "varchar2":
+		if len(typ.params) > 0 {
+			column.Length = typ.params[0]
+		}
+	case "decimal", "numeric", "float", "double", "number":
+		if len(typ.params) > 0 {
+			column.Precision = typ.params[0]
+		}
+		if len(typ.params) > 1 {
+			column.Scale = typ.params[1]
+		}
+	}
+}
+
+func (p *ddlParser) applyMySQLType(column *SchemaColumn, typ sqlType) {
+	dataType := typ.name
+	if alias, ok := mysqlTypeAliases[dataType]; ok {
+		dataType = alias
+	}
+	column.DatabaseType = dataType
+	column.ColumnType = strings.Join(append([]string{dataType + typ.args}, typ.mods...), " ")
+	switch typ.name {
+	case "bool", "boolean":
+		column.ColumnType = "tinyint(1)"
+	case "serial":
+		column.ColumnType = "bigint unsigned"
+		column.AutoIncrement = true
+		column.Nullable = false
+		column.Unique = true
+	}
+}
+
+// applyDMType 达梦的类型名不区分大小写，与系统视图一致使用大写
+func (p *ddlParser) applyDMType(column *SchemaColumn, typ sqlType) {
+	column.DatabaseType = typ.name
+	column.ColumnType = strings.ToUpper(typ.name) + typ.args
+}
+
+func (p *ddlParser) applyPostgresType(column *SchemaColumn, typ sqlType) {
+	udtName, display := typ.name, typ.name
+	if names, ok := postgresTypes[typ.name]; ok {
+		udtName, display = names[0], names[1]
+	}
+	if strings.Contains(typ.name, "serial") {
+		column.AutoIncrement = true
+		column.Nullable = false
+	}
+	if typ.args != "" {
+		// 时间类型的精度写在类型名第一个单词之后，如 timestamp(3) with time zone
+		if first, others, found := strings.Cut(display, " "); found && strings.HasPrefix(first, "time") {
+			display = first + typ.args + " " + others
+		} else {
+			display += typ.args
+		}
+	}
+	if typ.array {
+		udtName += "[]"
+		display += "[]"
+	}
+	column.DatabaseType = udtName
+	column.ColumnType = display
+}
+
+// createIndex CREATE [UNIQUE|FULLTEXT|SPATIAL] INDEX [CONCURRENTLY] [IF NOT EXISTS] [name] ON [ONLY] table [USING method] (columns)
+func (p *ddlParser) createIndex(s *sqlStmt) {
+	unique, option := s.accept("UNIQUE"), ""
+	if s.isAny("FULLTEXT", "SPATIAL") {
+		option = strings.ToUpper(s.next().text)
+	}
+	s.accept("INDEX")
+	s.accept("CONCURRENTLY")
+	s.accept("IF", "NOT", "EXISTS")
+	name := ""
+	if !s.isAny("ON", "USING") {
+		name = p.ident(s)
+	}
+	p.skipIndexType(s)
+	if !s.accept("ON") {
+		p.warn(s, "CREATE INDEX %s: missing ON clause", name)
+		return
+	}
+	s.accept("ONLY")
+	table := p.tableRef(s)
+	if table == nil {
+		return
+	}
+	p.skipIndexType(s)
+	if columns := p.indexColumns(table, s); columns != nil {
+		p.addIndex(table, name, columns, unique, false, option)
+	}
+}
+
+// alterTable ALTER TABLE [IF EXISTS] [ONLY] name action[, action ...]
+func (p *ddlParser) alterTable(s *sqlStmt) {
+	s.accept("IF", "EXISTS")
+	s.accept("ONLY")
+	table := p.tableRef(s)
+	if table == nil {
+		return
+	}
+	for _, action := range splitTopLevel(s.rest()) {
+		p.alterAction(table, &sqlStmt{tokens: action, index: s.index})
+	}
+}
+
+// ignoredAlterActions 不影响表结构的 ALTER TABLE 子句
+var ignoredAlterActions = []string{
+	"OWNER", "ENGINE", "AUTO_INCREMENT", "DEFAULT", "CHARSET", "CHARACTER", "COLLATE", "ROW_FORMAT", "SET", "RESET",
+	"ENABLE", "DISABLE", "CLUSTER", "REPLICA", "INHERIT", "NO", "ATTACH", "DETACH", "VALIDATE", "ALGORITHM", "LOCK",
+	"FORCE", "ORDER", "CONVERT",
+}
+
+// alterAction 一个 ALTER TABLE 子句
+func (p *ddlParser) alterAction(table *SchemaTable, a *sqlStmt) {
+	switch {
+	case a.accept("ADD"):
+		switch {
+		case a.accept("CONSTRAINT"):
+			p.tableConstraint(table, a, p.ident(a))
+		case a.isAny("PRIMARY", "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "FOREIGN", "CHECK", "EXCLUDE"):
+			p.tableConstraint(table, a, "")
+		default:
+			a.accept("COLUMN")
+			a.accept("IF", "NOT", "EXISTS")
+			p.addColumn(table, a)
+		}
+	case a.accept("DROP"):
+		switch {
+		case a.accept("PRIMARY", "KEY"):
+			p.dropPrimaryKey(table)
+		case a.accept("FOREIGN", "KEY"), a.accept("CHECK"):
+		case a.accept("CONSTRAINT"), a.accept("INDEX"), a.accept("KEY"):
+			a.accept("IF", "EXISTS")
+			p.dropIndexByName(table, p.ident(a))
+		default:
+			a.accept("COLUMN")
+			a.accept("IF", "EXISTS")
+			p.dropColumn(table, p.ident(a))
+		}
+	case a.accept("MODIFY"):
+		a.accept("COLUMN")
+		p.addColumn(table, &sqlStmt{tokens: p.keepPosition(table, a), index: a.index})
+	case a.accept("CHANGE"):
+		a.accept("COLUMN")
+		oldName := p.ident(a)
+		column, primaryKey, unique, _ := p.columnDefinition(table, a)
+		if column != nil {
+			if index := slices.IndexFunc(table.Columns, func(c *SchemaColumn) bool { return strings.EqualFold(c.Name, oldName) }); index >= 0 {
+				column.PrimaryKey = table.Columns[index].PrimaryKey
+				table.Columns[index] = column
+				p.renameIndexColumn(table, oldName, column.Name)
+			} else {
+				table.Columns = append(table.Columns, column)
+			}
+			p.columnConstraints(table, column, primaryKey, unique)
+		}
+	case a.accept("ALTER"):
+		a.accept("COLUMN")
+		p.alterColumn(table, a)
+	case a.accept("RENAME"):
+		p.rename(table, a)
+	case a.accept("COMMENT"):
+		a.accept("=")
+		table.Comment = a.next().text
+	case a.isAny(ignoredAlterActions...):
+	default:
+		p.warn(a, "ALTER TABLE %s: unsupported action %s", table.Name, joinTokens(a.rest()))
+	}
+}
+
+// keepPosition MODIFY/CHANGE 未指定 FIRST/AFTER 时保持字段原有位置
+func (p *ddlParser) keepPosition(table *SchemaTable, a *sqlStmt) []sqlToken {
+	tokens := a.rest()
+	for _, token := range tokens {
+		if token.is("FIRST") || token.is("AFTER") {
+			return tokens
+		}
+	}
+	if len(tokens) == 0 {
+		return tokens
+	}
+	name := p.fold(tokens[0])
+	index := slices.IndexFunc(table.Columns, func(c *SchemaColumn) bool { return strings.EqualFold(c.Name, name) })
+	switch {
+	case index == 0:
+		return append(slices.Clone(tokens), sqlToken{kind: tokWord, text: "FIRST", raw: "FIRST"})
+	case index > 0:
+		after := table.Columns[index-1].Name
+		return append(slices.Clone(tokens), sqlToken{kind: tokWord, text: "AFTER", raw: "AFTER"},
+			sqlToken{kind: tokIdent, text: after, raw: after})
+	}
+	return tokens
+}
+
+// alterColumn ALTER [COLUMN] name SET DEFAULT | DROP DEFAULT | SET/DROP NOT NULL | [SET DATA] TYPE | ADD GENERATED ... AS IDENTITY
+func (p *ddlParser) alterColumn(table *SchemaTable, a *sqlStmt) {
+	name := p.ident(a)
+	column := findSchemaColumn(table, name)
+	if column == nil {
+		p.warn(a, "ALTER TABLE %s: column %s is not defined", table.Name, name)
+		return
+	}
+	switch {
+	case a.accept("SET", "DEFAULT"):
+		column.Default = nil
+		if !a.accept("NULL") {
+			p.applyDefault(column, p.expression(a))
+		}
+	case a.accept("DROP", "DEFAULT"):
+		column.Default = nil
+	case a.accept("SET", "NOT", "NULL"):
+		column.Nullable = false
+	case a.accept("DROP", "NOT", "NULL"):
+		column.Nullable = true
+	case a.accept("SET", "DATA", "TYPE"), a.accept("TYPE"):
+		var typeTokens []sqlToken
+		for !a.eof() && !a.isAny("USING", "COLLATE") {
+			typeTokens = append(typeTokens, a.take()...)
+		}
+		p.applyType(column, typeTokens)
+	case a.accept("ADD", "GENERATED"):
+		column.AutoIncrement = true
+	case a.isAny("SET", "DROP", "RESET", "OPTIONS"):
+	default:
+		p.warn(a, "ALTER TABLE %s: unsupported ALTER COLUMN %s %s", table.Name, name, joinTokens(a.rest()))
+	}
+}
+
+// rename RENAME TO new | RENAME [COLUMN] old TO new | RENAME INDEX|KEY|CONSTRAINT old TO new
+func (p *ddlParser) rename(table *SchemaTable, a *sqlStmt) {
+	switch {
+	case a.accept("TO"), a.accept("AS"):
+		_, name := p.qualifiedName(a)
+		oldKey := buildFullTableName(table.Schema, table.Name)
+		delete(p.tables, oldKey)
+		table.Name = name
+		newKey := buildFullTableName(table.Schema, table.Name)
+		p.tables[newKey] = table
+		p.order[slices.Index(p.order, oldKey)] = newKey
+	case a.accept("INDEX"), a.accept("KEY"), a.accept("CONSTRAINT"):
+		oldName := p.ident(a)
+		a.accept("TO")
+		newName := p.ident(a)
+		for _, index := range table.Indexes {
+			if index.Name == oldName {
+				index.Name = newName
+			}
+		}
+	default:
+		a.accept("COLUMN")
+		oldName := p.ident(a)
+		a.accept("TO")
+		newName := p.ident(a)
+		if column := findSchemaColumn(table, oldName); column != nil {
+			column.Name = newName
+			p.renameIndexColumn(table, oldName, newName)
+		}
+	}
+}
+
+// renameIndexColumn 字段改名后同步主键与索引中的列名
+func (p *ddlParser) renameIndexColumn(table *SchemaTable, oldName, newName string) {
+	for i, name := range table.PrimaryKey {
+		if strings.EqualFold(name, oldName) {
+			table.PrimaryKey[i] = newName
+		}
+	}
+	for _, index := range table.Indexes {
+		for i, name := range index.Columns {
+			if strings.EqualFold(name, oldName) {
+				index.Columns[i] = newName
+			}
+		}
+	}
+}
+
+// dropColumn 删除字段及包含该字段的索引
+func (p *ddlParser) dropColumn(table *SchemaTable, name string) {
+	table.Columns = slices.DeleteFunc(table.Columns, func(c *SchemaColumn) bool { return strings.EqualFold(c.Name, name) })
+	table.PrimaryKey = slices.DeleteFunc(table.PrimaryKey, func(c string) bool { return strings.EqualFold(c, name) })
+	table.Indexes = slices.DeleteFunc(table.Indexes, func(index *SchemaIndex) bool {
+		return slices.ContainsFunc(index.Columns, func(c string) bool { return strings.EqualFold(c, name) })
+	})
+}
+
+// dropTable DROP TABLE [IF EXISTS] name[, name ...] [CASCADE]
+func (p *ddlParser) dropTable(s *sqlStmt) {
+	s.accept("IF", "EXISTS")
+	for {
+		key := buildFullTableName(p.qualifiedName(s))
+		if _, ok := p.tables[key]; ok {
+			delete(p.tables, key)
+			p.order = slices.DeleteFunc(p.order, func(k string) bool { return k == key })
+		}
+		if !s.accept(",") {
+			return
+		}
+	}
+}
+
+// dropIndex DROP INDEX [CONCURRENTLY] [IF EXISTS] name[, ...] | DROP INDEX name ON table
+func (p *ddlParser) dropIndex(s *sqlStmt) {
+	s.accept("CONCURRENTLY")
+	s.accept("IF", "EXISTS")
+	for {
+		schema, name := p.qualifiedName(s)
+		if s.accept("ON") {
+			if table := p.tableRef(s); table != nil {
+				p.dropIndexByName(table, name)
+			}
+			return
+		}
+		for _, key := range p.order {
+			if table := p.tables[key]; table.Schema == schema && p.dropIndexByName(table, name) {
+				break
+			}
+		}
+		if !s.accept(",") {
+			return
+		}
+	}
+}
+
+// commentOn COMMENT ON TABLE name IS '...' | COMMENT ON COLUMN table.column IS '...'
+func (p *ddlParser) commentOn(s *sqlStmt) {
+	switch {
+	case s.accept("TABLE"):
+		table := p.tableRef(s)
+		if table != nil && s.accept("IS") {
+			table.Comment = s.next().text
+		}
+	case s.accept("COLUMN"):
+		parts := p.nameParts(s)
+		if len(parts) < 2 {
+			p.warn(s, "COMMENT ON COLUMN %s: missing table name", parts[0])
+			return
+		}
+		schema := p.defaultSchema
+		if len(parts) > 2 {
+			schema = parts[len(parts)-3]
+		}
+		table := p.lookupTable(s, schema, parts[len(parts)-2])
+		if table == nil || !s.accept("IS") {
+			return
+		}
+		if column := findSchemaColumn(table, parts[len(parts)-1]); column != nil {
+			column.Comment = s.next().text
+		}
+	}
+}
+
+// database 按 schema 汇总解析出的表
+func (p *ddlParser) database(dbName, dbType string) *InspectDatabase {
+	database := &InspectDatabase{DBName: dbName, DBType: dbType}
+	schemas := make(map[string]*InspectSchema)
+	for _, key := range p.order {
+		table := p.tables[key]
+		schema, ok := schemas[table.Schema]
+		if !ok {
+			schema = &InspectSchema{Name: table.Schema}
+			schemas[table.Schema] = schema
+			database.Schemas = append(database.Schemas, schema)
+		}
+		schema.Tables = append(schema.Tables, table)
+	}
+	return database
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// describeTable 以紧凑的文本描述表结构，便于比较
+func describeTable(table *SchemaTable) []string {
+	lines := []string{buildFullTableName(table.Schema, table.Name)}
+	if table.Comment != "" {
+		lines[0] += " -- " + table.Comment
+	}
+	for _, column := range table.Columns {
+		line := fmt.Sprintf("%s %s", column.Name, column.ColumnType)
+		if !column.Nullable {
+			line += " NOT NULL"
+		}
+		if column.Default != nil {
+			line += " DEFAULT " + *column.Default
+		}
+		if column.AutoIncrement {
+			line += " AUTO"
+		}
+		if column.Unique {
+			line += " UNIQUE"
+		}
+		if column.ReadOnly {
+			line += " GENERATED"
+		}
+		if column.Comment != "" {
+			line += " -- " + column.Comment
+		}
+		lines = append(lines, line)
+	}
+	if len(table.PrimaryKey) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+strings.Join(table.PrimaryKey, ",")+")")
+	}
+	for _, index := range table.Indexes {
+		kind := "INDEX"
+		switch {
+		case index.PrimaryKey:
+			kind = "PK INDEX"
+		case index.Unique:
+			kind = "UNIQUE"
+		case index.Option != "":
+			kind = index.Option
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%s)", kind, index.Name, strings.Join(index.Columns, ",")))
+	}
+	return lines
+}
+
+func parseDDL(t *testing.T, dbType gormx.DBType, content string) *ddlParser {
+	t.Helper()
+	parser, err := newDDLParser(dbType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.parse("test.sql", content)
+	return parser
+}
+
+func TestDDLParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   gormx.DBType
+		content  string
+		want     map[string][]string
+		warnings int
+	}{
+		{
+			name:   "mysql create table",
+			dbType: gormx.DBTypeMySQL,
+			content: "CREATE TABLE `user` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',\n" +
+				"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'it''s',\n" +
+				"  `enabled` tinyint(1) DEFAULT 1,\n" +
+				"  `flag` bit(1) DEFAULT b'0',\n" +
+				"  `price` decimal(10,2) DEFAULT '0.00',\n" +
+				"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+				"  `total` int GENERATED ALWAYS AS (price * 2) VIRTUAL,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  UNIQUE KEY `uk_name` (`name`),\n" +
+				"  KEY `idx_updated` (`updated_at`) USING BTREE,\n" +
+				"  FULLTEXT KEY (`name`),\n" +
+				"  CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `other` (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户';",
+			want: map[string][]string{"user": {
+				"user -- 用户",
+				"id bigint unsigned NOT NULL AUTO -- 主键",
+				"name varchar(64) NOT NULL DEFAULT  UNIQUE -- it's",
+				"enabled tinyint(1) DEFAULT 1",
+				"flag bit(1) DEFAULT b'0'",
+				"price decimal(10,2) DEFAULT 0.00",
+				"updated_at timestamp DEFAULT CURRENT_TIMESTAMP",
+				"total int GENERATED",
+				"PRIMARY KEY (id)",
+				"PK INDEX PRIMARY (id)",
+				"UNIQUE uk_name (name)",
+				"INDEX idx_updated (updated_at)",
+				"FULLTEXT name (name)",
+			}},
+		},
+		{
+			name:   "mysql serial and aliases",
+			dbType: gormx.DBTypeMySQL,
+			content: "CREATE TABLE t (id serial, flag boolean, n integer, r real);" +
+				"CREATE TABLE t2 LIKE t;",
+			want: map[string][]string{
+				"t": {
+					"t",
+					"id bigint unsigned NOT NULL AUTO UNIQUE",
+					"flag tinyint(1)",
+					"n int",
+					"r double",
+					"UNIQUE id (id)",
+				},
+				"t2": {
+					"t2",
+					"id bigint unsigned NOT NULL AUTO UNIQUE",
+					"flag tinyint(1)",
+					"n int",
+					"r double",
+					"UNIQUE id (id)",
+				},
+			},
+		},
+		{
+			name:   "mysql alter table",
+			dbType: gormx.DBTypeMySQL,
+			content: "CREATE TABLE t (id int NOT NULL, a varchar(10), b int, PRIMARY KEY (id));\n" +
+				"ALTER TABLE t ADD COLUMN c int NOT NULL DEFAULT 0 AFTER id, ADD INDEX idx_b (b);\n" +
+				"ALTER TABLE t MODIFY a varchar(20) NOT NULL;\n" +
+				"ALTER TABLE t CHANGE b b2 bigint COMMENT 'renamed';\n" +
+				"ALTER TABLE t ADD COLUMN first_col int FIRST, DROP COLUMN c;\n" +
+				"ALTER TABLE t RENAME INDEX idx_b TO idx_b2, COMMENT = 'tt', ENGINE = InnoDB;\n" +
+				"ALTER TABLE t RENAME TO t_new;",
+			want: map[string][]string{"t_new": {
+				"t_new -- tt",
+				"first_col int",
+				"id int NOT NULL",
+				"a varchar(20) NOT NULL",
+				"b2 bigint -- renamed",
+				"PRIMARY KEY (id)",
+				"PK INDEX PRIMARY (id)",
+				"INDEX idx_b2 (b2)",
+			}},
+		},
+		{
+			name:   "mysql create and drop index",
+			dbType: gormx.DBTypeMySQL,
+			content: "CREATE TABLE t (a int, b int, c int);\n" +
+				"CREATE UNIQUE INDEX uk_ab ON t (a, b);\n" +
+				"CREATE INDEX idx_c ON t (c(10) DESC);\n" +
+				"CREATE INDEX idx_gone ON t (a);\n" +
+				"DROP INDEX idx_gone ON t;",
+			want: map[string][]string{"t": {
+				"t",
+				"a int",
+				"b int",
+				"c int",
+				"UNIQUE uk_ab (a,b)",
+				"INDEX idx_c (c)",
+			}},
+		},
+		{
+			name:   "postgres create table",
+			dbType: gormx.DBTypePostgres,
+			content: `CREATE TABLE IF NOT EXISTS public."Order" (
+				id bigserial PRIMARY KEY,
+				Code VARCHAR(32) NOT NULL DEFAULT 'N/A'::character varying,
+				amount numeric(10, 2),
+				created_at timestamp(3) with time zone DEFAULT now(),
+				tags text[],
+				ref integer REFERENCES other (id) ON DELETE CASCADE,
+				seq int GENERATED BY DEFAULT AS IDENTITY,
+				CONSTRAINT uq_code UNIQUE (code),
+				CHECK (amount > 0)
+			);`,
+			want: map[string][]string{"public.Order": {
+				"public.Order",
+				"id bigint NOT NULL AUTO",
+				"code character varying(32) NOT NULL DEFAULT 'N/A'::character varying UNIQUE",
+				"amount numeric(10,2)",
+				"created_at timestamp(3) with time zone DEFAULT now()",
+				"tags text[]",
+				"ref integer",
+				"seq integer NOT NULL AUTO",
+				"PRIMARY KEY (id)",
+				"PK INDEX Order_pkey (id)",
+				"UNIQUE uq_code (code)",
+			}},
+		},
+		{
+			name:   "postgres alter, index and comments",
+			dbType: gormx.DBTypePostgres,
+			content: `CREATE TABLE app.item (id int, name text, price int);
+				ALTER TABLE ONLY app.item ADD CONSTRAINT item_pk PRIMARY KEY (id);
+				ALTER TABLE app.item ALTER COLUMN price TYPE numeric(8,2), ALTER COLUMN price SET DEFAULT 0,
+					ALTER COLUMN name SET NOT NULL, ADD COLUMN IF NOT EXISTS note varchar(100);
+				ALTER TABLE app.item RENAME COLUMN note TO remark;
+				CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS item_name_idx ON app.item USING btree (name);
+				CREATE INDEX item_lower_idx ON app.item (lower(name));
+				COMMENT ON TABLE app.item IS 'Items';
+				COMMENT ON COLUMN app.item.remark IS 'Remark';
+				CREATE TABLE app.tmp (id int);
+				DROP TABLE IF EXISTS app.tmp;`,
+			want: map[string][]string{"app.item": {
+				"app.item -- Items",
+				"id integer NOT NULL",
+				"name text NOT NULL",
+				"price numeric(8,2) DEFAULT 0",
+				"remark character varying(100) -- Remark",
+				"PRIMARY KEY (id)",
+				"PK INDEX item_pk (id)",
+				"UNIQUE item_name_idx (name)",
+			}},
+			warnings: 1,
+		},
+		{
+			name:   "postgres partition of",
+			dbType: gormx.DBTypePostgres,
+			content: `CREATE TABLE log (id int NOT NULL, at date) PARTITION BY RANGE (at);
+				CREATE TABLE log_2024 PARTITION OF log FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');`,
+			want: map[string][]string{
+				"public.log":      {"public.log", "id integer NOT NULL", "at date"},
+				"public.log_2024": {"public.log_2024", "id integer NOT NULL", "at date"},
+			},
+		},
+		{
+			name:   "dm create table",
+			dbType: gormx.DBTypeDM,
+			content: `CREATE TABLE "SYSDBA"."T_USER" (
+				"ID" BIGINT IDENTITY(1, 1) NOT NULL,
+				user_name VARCHAR(50 CHAR) DEFAULT '' NOT NULL,
+				"CREATED" TIMESTAMP(0) DEFAULT SYSDATE,
+				NOT CLUSTER PRIMARY KEY("ID")) STORAGE(ON "MAIN", CLUSTERBTR);
+				CREATE INDEX "IDX_NAME" ON "SYSDBA"."T_USER"("USER_NAME" ASC) STORAGE(ON "MAIN", CLUSTERBTR);
+				COMMENT ON COLUMN "SYSDBA"."T_USER"."USER_NAME" IS '名称';`,
+			want: map[string][]string{"SYSDBA.T_USER": {
+				"SYSDBA.T_USER",
+				"ID BIGINT NOT NULL AUTO",
+				"USER_NAME VARCHAR(50 CHAR) NOT NULL DEFAULT '' -- 名称",
+				"CREATED TIMESTAMP(0) DEFAULT SYSDATE",
+				"PRIMARY KEY (ID)",
+				"PK INDEX T_USER_pkey (ID)",
+				"INDEX IDX_NAME (USER_NAME)",
+			}},
+		},
+		{
+			name:     "undefined table",
+			dbType:   gormx.DBTypePostgres,
+			content:  "ALTER TABLE missing ADD COLUMN a int; COMMENT ON TABLE missing IS 'x';",
+			want:     map[string][]string{},
+			warnings: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := parseDDL(t, tt.dbType, tt.content)
+			got := make(map[string][]string)
+			for key, table := range parser.tables {
+				got[key] = describeTable(table)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tables =\n%s\nwant\n%s", formatTables(got), formatTables(tt.want))
+			}
+			if len(parser.warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", parser.warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func formatTables(tables map[string][]string) string {
+	var sb strings.Builder
+	for key, lines := range tables {
+		fmt.Fprintf(&sb, "[%s]\n  %s\n", key, strings.Join(lines, "\n  "))
+	}
+	return sb.String()
+}
+
+func TestDDLParserTranslateWarnings(t *testing.T) {
+	content := "CREATE TABLE t (a int CHECK (a > 0), b timestamp ON UPDATE CURRENT_TIMESTAMP," +
+		" FOREIGN KEY (a) REFERENCES o (id)) ENGINE=InnoDB PARTITION BY HASH(a);" +
+		"CREATE VIEW v AS SELECT 1; SET NAMES utf8mb4;"
+	tests := []struct {
+		translate bool
+		want      []string
+	}{
+		{false, nil},
+		{true, []string{
+			"test.sql: statement 1: column t.a: CHECK (a > 0) is dropped",
+			"test.sql: statement 1: column t.b: ON UPDATE CURRENT_TIMESTAMP is dropped",
+			"test.sql: statement 1: table t: constraint FOREIGN KEY(a) REFERENCES o(id) is dropped",
+			"test.sql: statement 1: table t: table options PARTITION BY HASH(a) are dropped",
+			"test.sql: statement 2: statement CREATE VIEW v is skipped",
+		}},
+	}
+	for _, tt := range tests {
+		parser, _ := newDDLParser(gormx.DBTypeMySQL)
+		parser.translate = tt.translate
+		parser.parse("test.sql", content)
+		if !reflect.DeepEqual(parser.warnings, tt.want) {
+			t.Errorf("translate=%v: warnings = %q, want %q", tt.translate, parser.warnings, tt.want)
+		}
+	}
+}
+
+func TestNewDDLParserUnsupported(t *testing.T) {
+	if _, err := newDDLParser(gormx.DBTypeSqlserver); err == nil {
+		t.Error("newDDLParser(sqlserver) should fail")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pborman/getopt/v2"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// processDDLTranslate ddl translate 子命令：解析源数据库的建表 SQL，输出目标数据库的建表语句
+func processDDLTranslate() {
+	os.Args = append(os.Args[:1:1], os.Args[3:]...)
+	from := getopt.StringLong("from", 0, "", "source database type [mysql | postgres | greenplum | dm]")
+	to := getopt.StringLong("to", 0, "", "target database type [mysql | postgres | greenplum | sqlserver | oracle | dm | sqlite]")
+	output := getopt.StringLong("output", 'o', "", "write the DDL to file instead of stdout")
+	paths := parseInterspersed()
+	if *from == "" || *to == "" {
+		log.Fatal("缺少必要参数: --from, --to")
+	}
+	if len(paths) == 0 {
+		log.Fatal("缺少 SQL 文件或目录")
+	}
+
+	parser, err := newDDLParser(gormx.DBType(*from))
+	if err != nil {
+		log.Fatal(err)
+	}
+	parser.translate = true
+	writer, err := newDDLWriter(gormx.DBType(*to), gormx.DBType(*from))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, path := range paths {
+		files, err := sqlFiles(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			parser.parse(file, string(content))
+		}
+	}
+	if len(parser.order) == 0 {
+		log.Fatalf("%s 中没有建表语句", strings.Join(paths, " "))
+	}
+
+	translateTables(parser, writer, gormx.DBType(*from), gormx.DBType(*to))
+
+	header := fmt.Sprintf("-- Code generated by jasonlabz/gentol. DO NOT EDIT.\n-- %s DDL translated from %s %s\n\n",
+		*to, *from, strings.Join(paths, " "))
+	writeDDLOutput(*output, header+strings.TrimSuffix(writer.String(), "\n"))
+
+	untranslated := append(parser.warnings, writer.warnings...)
+	if len(untranslated) > 0 {
+		log.Printf("以下 %d 处内容未能翻译，请人工确认:", len(untranslated))
+		for _, warning := range untranslated {
+			log.Printf("  %s", warning)
+		}
+	}
+	log.Printf("翻译 %d 张表", len(parser.order))
+}
+
+// translateTables 按建表顺序将解析出的表写为目标数据库的建表语句
+func translateTables(parser *ddlParser, writer *ddlWriter, from, to gormx.DBType) {
+	for _, key := range parser.order {
+		table := parser.tables[key]
+		// 源数据库的默认 schema 不写出，由目标数据库的连接决定
+		if table.Schema == parser.defaultSchema {
+			table.Schema = ""
+		}
+		foldNames(table, from, to)
+		writer.writeTable(table)
+	}
+}
+
+// identCase 数据库未加引号的标识符的大小写：Oracle、达梦为大写，其余为小写
+func identCase(dbType gormx.DBType) func(string) string {
+	if dbType == gormx.DBTypeOracle || dbType == gormx.DBTypeDM {
+		return strings.ToUpper
+	}
+	return strings.ToLower
+}
+
+// foldNames 源数据库中未区分大小写的名称转为目标数据库的习惯写法，如达梦的 USER_INFO 转为 PostgreSQL 的 user_info，
+// 大小写混合的名称保持不变
+func foldNames(table *SchemaTable, from, to gormx.DBType) {
+	source, target := identCase(from), identCase(to)
+	fold := func(name string) string {
+		if name == source(name) {
+			return target(name)
+		}
+		return name
+	}
+	foldAll := func(names []string) {
+		for i, name := range names {
+			names[i] = fold(name)
+		}
+	}
+	table.Schema, table.Name = fold(table.Schema), fold(table.Name)
+	for _, column := range table.Columns {
+		column.Name = fold(column.Name)
+	}
+	foldAll(table.PrimaryKey)
+	for _, index := range table.Indexes {
+		index.Name = fold(index.Name)
+		foldAll(index.Columns)
+	}
+}
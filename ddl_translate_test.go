@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+func translateDDLFixture(t *testing.T, from, to gormx.DBType, content string) (string, []string) {
+	t.Helper()
+	parser, err := newDDLParser(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.translate = true
+	parser.parse("fixture.sql", content)
+	writer, err := newDDLWriter(to, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translateTables(parser, writer, from, to)
+	return writer.String(), append(parser.warnings, writer.warnings...)
+}
+
+func TestDDLTranslate(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to gormx.DBType
+		sql      string
+		want     string
+		warnings []string
+	}{
+		{
+			name: "mysql to postgres",
+			from: gormx.DBTypeMySQL,
+			to:   gormx.DBTypePostgres,
+			sql: "CREATE TABLE `user` (\n" +
+				"  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+				"  `order` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '订单号',\n" +
+				"  `userName` VARCHAR(64),\n" +
+				"  `enabled` TINYINT(1) NOT NULL DEFAULT 1,\n" +
+				"  `balance` DECIMAL(10,2) DEFAULT 0.00,\n" +
+				"  `profile` JSON,\n" +
+				"  `note` LONGTEXT,\n" +
+				"  `avatar` BLOB,\n" +
+				"  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  UNIQUE KEY `uk_order` (`order`),\n" +
+				"  KEY `idx_user_name` (`userName`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户';\n",
+			want: `CREATE TABLE IF NOT EXISTS "user" (
+    id         BIGSERIAL     NOT NULL,
+    "order"    VARCHAR(32)   DEFAULT '' NOT NULL,
+    "userName" VARCHAR(64),
+    enabled    BOOLEAN       DEFAULT TRUE NOT NULL,
+    balance    NUMERIC(10,2) DEFAULT 0.00,
+    profile    JSONB,
+    note       TEXT,
+    avatar     BYTEA,
+    created_at TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_order ON "user" ("order");
+CREATE INDEX IF NOT EXISTS idx_user_name ON "user" ("userName");
+COMMENT ON TABLE "user" IS '用户';
+COMMENT ON COLUMN "user"."order" IS '订单号';
+`,
+		},
+		{
+			name: "postgres to mysql",
+			from: gormx.DBTypePostgres,
+			to:   gormx.DBTypeMySQL,
+			sql: `CREATE TABLE public."Order" (
+  id BIGSERIAL PRIMARY KEY,
+  "desc" TEXT NOT NULL,
+  tags VARCHAR(16)[],
+  paid BOOLEAN DEFAULT false,
+  CONSTRAINT fk_user FOREIGN KEY (id) REFERENCES "user"(id)
+);
+CREATE TABLE sales.item (id INT);`,
+			want: "CREATE TABLE IF NOT EXISTS `Order` (\n" +
+				"    id     BIGINT     NOT NULL AUTO_INCREMENT,\n" +
+				"    `desc` LONGTEXT   NOT NULL,\n" +
+				"    tags   JSON,\n" +
+				"    paid   TINYINT(1) DEFAULT 0,\n" +
+				"    PRIMARY KEY (id)\n" +
+				");\n\n" +
+				"CREATE TABLE IF NOT EXISTS sales.item (\n" +
+				"    id INT\n" +
+				");\n",
+			warnings: []string{"REFERENCES", "array column tags"},
+		},
+		{
+			name: "dm to postgres folds upper case names",
+			from: gormx.DBTypeDM,
+			to:   gormx.DBTypePostgres,
+			sql:  `CREATE TABLE USER_INFO (ID INT NOT NULL, "NickName" VARCHAR(32), PRIMARY KEY (ID));`,
+			want: `CREATE TABLE IF NOT EXISTS user_info (
+    id         INTEGER     NOT NULL,
+    "NickName" VARCHAR(32),
+    PRIMARY KEY (id)
+);
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := translateDDLFixture(t, tt.from, tt.to, tt.sql)
+			if got = strings.TrimSuffix(got, "\n"); got != tt.want {
+				t.Errorf("translate DDL =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("warnings = %q, want %d warnings", warnings, len(tt.warnings))
+			}
+			for i, want := range tt.warnings {
+				if !strings.Contains(warnings[i], want) {
+					t.Errorf("warning[%d] = %q, want it to mention %q", i, warnings[i], want)
+				}
+			}
+		})
+	}
+}
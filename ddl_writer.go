@@ -0,0 +1,920 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jasonlabz/gentol/gormx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// ddlType 字段的逻辑类型，各数据库之间转换的中间表示
+type ddlType struct {
+	kind      string // bool tinyint smallint int bigint float double decimal char varchar text date time datetime timestamptz bytes json uuid
+	unsigned  bool
+	length    int64 // char、varchar、bytes 的长度
+	precision int64 // decimal 的精度，时间类型的秒精度
+	scale     int64
+	array     bool // PostgreSQL 数组
+}
+
+// ddlKinds 各数据库的类型名 -> 逻辑类型，未列出的类型名由对应数据库的类型映射（MySQLTrans 等）判断
+var ddlKinds = map[string]string{
+	"bool": "bool", "boolean": "bool", "bit": "bool",
+	"tinyint": "tinyint", "smallint": "smallint", "mediumint": "int", "int": "int", "integer": "int", "bigint": "bigint",
+	"real": "float", "double": "double", "double precision": "double",
+	"decimal": "decimal", "numeric": "decimal", "number": "decimal", "dec": "decimal",
+	"char": "char", "character": "char", "bpchar": "char", "nchar": "char",
+	"varchar": "varchar", "character varying": "varchar", "varchar2": "varchar", "nvarchar": "varchar", "nvarchar2": "varchar",
+	"text": "text", "tinytext": "text", "mediumtext": "text", "longtext": "text", "clob": "text", "nclob": "text",
+	"ntext": "text", "citext": "text", "long": "text",
+	"date": "date", "year": "smallint",
+	"time": "time", "time without time zone": "time", "timetz": "time", "time with time zone": "time",
+	"datetime": "datetime", "datetime2": "datetime", "smalldatetime": "datetime",
+	"timestamp": "datetime", "timestamp without time zone": "datetime",
+	"timestamptz": "timestamptz", "timestamp with time zone": "timestamptz",
+	"datetimeoffset": "timestamptz", "timestamp with local time zone": "timestamptz",
+	"binary": "bytes", "varbinary": "bytes", "blob": "bytes", "tinyblob": "bytes", "mediumblob": "bytes",
+	"longblob": "bytes", "bytea": "bytes", "raw": "bytes", "long raw": "bytes", "image": "bytes",
+	"json": "json", "jsonb": "json",
+	"uuid": "uuid", "uniqueidentifier": "uuid",
+}
+
+// goKinds 类型映射得到的 Go 类型 -> 逻辑类型
+var goKinds = map[string]string{
+	"bool": "bool", "int8": "tinyint", "int16": "smallint", "int32": "int", "int": "bigint", "int64": "bigint",
+	"uint8": "tinyint", "uint16": "smallint", "uint32": "int", "uint": "bigint", "uint64": "bigint",
+	"float32": "float", "float64": "double", "time.Time": "datetime", "[]byte": "bytes",
+}
+
+// parseDDLType 解析字段类型原文，dbType 为类型所属的数据库，未知时为空；无法识别时返回 false
+func parseDDLType(dbType gormx.DBType, columnType string) (ddlType, bool) {
+	statements := splitStatements(columnType, dbType)
+	if len(statements) == 0 {
+		return ddlType{}, false
+	}
+	typ := splitType(statements[0])
+	result := ddlType{array: typ.array, unsigned: slices.Contains(typ.mods, "unsigned")}
+	kind, ok := ddlKinds[typ.name]
+	if !ok {
+		// 各数据库特有的类型别名，如 int4、float8、int1
+		if dbType == "" {
+			dbType = gormx.DBTypeMySQL
+		}
+		metaType := metadata.GetMetaType(dbType, typ.name)
+		if kind, ok = goKinds[metaType.GoType]; !ok {
+			return result, false
+		}
+	}
+	result.kind = kind
+	switch kind {
+	case "tinyint":
+		// MySQL 的 tinyint(1) 即布尔类型
+		if len(typ.params) == 1 && typ.params[0] == 1 && (dbType == gormx.DBTypeMySQL || dbType == "") {
+			result.kind = "bool"
+		}
+	case "char", "varchar", "bytes", "time", "datetime", "timestamptz":
+		if len(typ.params) > 0 {
+			result.length = typ.params[0]
+		}
+	case "decimal":
+		if len(typ.params) > 0 {
+			result.precision = typ.params[0]
+		}
+		if len(typ.params) > 1 {
+			result.scale = typ.params[1]
+		}
+		// Oracle、达梦的 NUMBER(p) 为整数
+		if typ.name == "number" && len(typ.params) == 1 {
+			result.kind = numberKind(typ.params[0])
+		}
+	case "double":
+		if len(typ.params) > 0 {
+			result.kind, result.precision = "decimal", typ.params[0]
+			if len(typ.params) > 1 {
+				result.scale = typ.params[1]
+			}
+		}
+	}
+	// 时间类型的括号参数为秒精度
+	if result.kind == "time" || result.kind == "datetime" || result.kind == "timestamptz" {
+		result.precision, result.length = result.length, 0
+	}
+	return result, true
+}
+
+// numberKind NUMBER(p) 按位数对应的整数类型
+func numberKind(precision int64) string {
+	switch {
+	case precision == 1:
+		return "bool"
+	case precision <= 3:
+		return "tinyint"
+	case precision <= 5:
+		return "smallint"
+	case precision <= 10:
+		return "int"
+	case precision <= 19:
+		return "bigint"
+	}
+	return "decimal"
+}
+
+// sameDialect 两种数据库的类型语法是否相同
+func sameDialect(a, b gormx.DBType) bool {
+	family := func(dbType gormx.DBType) gormx.DBType {
+		if dbType == gormx.DBTypeGreenplum {
+			return gormx.DBTypePostgres
+		}
+		return dbType
+	}
+	return family(a) == family(b)
+}
+
+// ddlWriter 将表结构输出为指定数据库的建表语句，无法转换的内容记录到 warnings
+type ddlWriter struct {
+	dbType     gormx.DBType
+	source     gormx.DBType // 表结构中原始类型、默认值所属的数据库，来自 Go 结构体时为空
+	warnings   []string
+	indexNames map[string]bool // 已使用的索引名，PostgreSQL 等索引名在 schema 内唯一
+	sb         strings.Builder
+}
+
+// newDDLWriter 支持 gormx 中的全部数据库类型
+func newDDLWriter(dbType, source gormx.DBType) (*ddlWriter, error) {
+	switch dbType {
+	case gormx.DBTypeMySQL, gormx.DBTypePostgres, gormx.DBTypeGreenplum, gormx.DBTypeSqlserver,
+		gormx.DBTypeOracle, gormx.DBTypeDM, gormx.DBTypeSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported db_type %q", dbType)
+	}
+	return &ddlWriter{dbType: dbType, source: source, indexNames: make(map[string]bool)}, nil
+}
+
+func (w *ddlWriter) warn(table *SchemaTable, format string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf("%s: %s", buildFullTableName(table.Schema, table.Name), fmt.Sprintf(format, args...)))
+}
+
+func (w *ddlWriter) String() string {
+	return w.sb.String()
+}
+
+// reservedWords 常见的保留字，作为标识符时需要加引号
+var reservedWords = map[string]bool{
+	"user": true, "order": true, "group": true, "table": true, "select": true, "key": true, "index": true,
+	"desc": true, "asc": true, "from": true, "where": true, "to": true, "check": true, "default": true,
+	"references": true, "primary": true, "unique": true, "column": true, "constraint": true, "limit": true,
+	"offset": true, "range": true, "comment": true, "level": true, "size": true, "number": true, "date": true,
+	"time": true, "timestamp": true, "type": true, "value": true, "values": true, "option": true, "end": true,
+	"view": true, "session": true, "file": true, "mode": true, "rows": true, "start": true, "action": true,
+	"by": true, "in": true, "on": true, "is": true, "as": true, "and": true, "or": true, "not": true, "null": true,
+	"all": true, "any": true, "case": true, "when": true, "then": true, "else": true, "like": true, "join": true,
+	"into": true, "set": true, "update": true, "delete": true, "insert": true, "create": true, "drop": true,
+	"alter": true, "grant": true, "for": true, "with": true, "distinct": true, "having": true, "union": true,
+}
+
+var (
+	lowerIdentPattern = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+	upperIdentPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_$#]*$`)
+	mixedIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+)
+
+// quote 按需为标识符加引号：PostgreSQL 未加引号的标识符转为小写，Oracle、达梦转为大写
+func (w *ddlWriter) quote(name string) string {
+	plain := false
+	switch w.dbType {
+	case gormx.DBTypePostgres, gormx.DBTypeGreenplum:
+		plain = lowerIdentPattern.MatchString(name)
+	case gormx.DBTypeOracle, gormx.DBTypeDM:
+		plain = upperIdentPattern.MatchString(name)
+	default:
+		plain = mixedIdentPattern.MatchString(name)
+	}
+	if plain && !reservedWords[strings.ToLower(name)] {
+		return name
+	}
+	switch w.dbType {
+	case gormx.DBTypeMySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case gormx.DBTypeSqlserver:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// tableName 表名，省略目标数据库的默认 schema
+func (w *ddlWriter) tableName(table *SchemaTable) string {
+	switch table.Schema {
+	case "":
+		return w.quote(table.Name)
+	case "public":
+		if w.dbType != gormx.DBTypePostgres && w.dbType != gormx.DBTypeGreenplum {
+			return w.quote(table.Name)
+		}
+	case "dbo":
+		if w.dbType != gormx.DBTypeSqlserver {
+			return w.quote(table.Name)
+		}
+	}
+	return w.quote(table.Schema) + "." + w.quote(table.Name)
+}
+
+// literal 字符串字面量
+func (w *ddlWriter) literal(value string) string {
+	value = strings.ReplaceAll(value, "'", "''")
+	if w.dbType == gormx.DBTypeMySQL {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	if w.dbType == gormx.DBTypeSqlserver {
+		return "N'" + value + "'"
+	}
+	return "'" + value + "'"
+}
+
+// columnType 字段的逻辑类型；类型原文无法识别时返回 false
+func (w *ddlWriter) columnType(column *SchemaColumn) (ddlType, bool) {
+	if column.ColumnType != "" {
+		typ, ok := parseDDLType(w.source, column.ColumnType)
+		if ok {
+			if typ.length == 0 && (typ.kind == "char" || typ.kind == "varchar" || typ.kind == "bytes") {
+				typ.length = column.Length
+			}
+			if typ.kind == "decimal" && typ.precision == 0 {
+				typ.precision, typ.scale = column.Precision, column.Scale
+			}
+		}
+		return typ, ok
+	}
+	goType := strings.TrimPrefix(column.GoType, "*")
+	typ := ddlType{kind: goKinds[goType], unsigned: strings.HasPrefix(goType, "uint")}
+	switch {
+	case column.Precision > 0 && (typ.kind == "float" || typ.kind == "double"):
+		typ.kind, typ.precision, typ.scale = "decimal", column.Precision, column.Scale
+	case typ.kind == "datetime":
+		typ.precision = column.Precision
+	case goType == "string":
+		typ.kind, typ.length = "text", column.Length
+		if column.Length > 0 {
+			typ.kind = "varchar"
+		}
+	case typ.kind == "bytes":
+		typ.length = column.Length
+	}
+	return typ, typ.kind != ""
+}
+
+// renderType 逻辑类型在目标数据库中的类型；keyed 表示字段属于主键或索引
+func (w *ddlWriter) renderType(table *SchemaTable, column *SchemaColumn, typ ddlType, keyed bool) string {
+	sized := func(name string, size int64, fallback string) string {
+		if size > 0 {
+			return fmt.Sprintf("%s(%d)", name, size)
+		}
+		return fallback
+	}
+	decimal := func(name string) string {
+		switch {
+		case typ.precision > 0 && typ.scale > 0:
+			return fmt.Sprintf("%s(%d,%d)", name, typ.precision, typ.scale)
+		case typ.precision > 0:
+			return fmt.Sprintf("%s(%d)", name, typ.precision)
+		}
+		return name
+	}
+	precision := func(name string) string {
+		if typ.precision > 0 {
+			return fmt.Sprintf("%s(%d)", name, typ.precision)
+		}
+		return name
+	}
+	if typ.array && w.dbType != gormx.DBTypePostgres && w.dbType != gormx.DBTypeGreenplum {
+		w.warn(table, "array column %s is written as JSON", column.Name)
+		typ = ddlType{kind: "json"}
+	}
+	if typ.unsigned && w.dbType != gormx.DBTypeMySQL {
+		// 无符号整数放大一级以容纳取值范围
+		switch typ.kind {
+		case "tinyint":
+			typ.kind = "smallint"
+		case "smallint":
+			typ.kind = "int"
+		case "int":
+			typ.kind = "bigint"
+		case "bigint":
+			// Go 的 uint64 与 gorm 一致映射为 bigint，只提示来自数据库的无符号字段
+			if w.source != "" {
+				w.warn(table, "column %s: unsigned bigint is written as signed bigint", column.Name)
+			}
+		}
+	}
+
+	var result string
+	switch w.dbType {
+	case gormx.DBTypeMySQL:
+		switch typ.kind {
+		case "bool":
+			result = "TINYINT(1)"
+		case "tinyint", "smallint", "int", "bigint":
+			result = strings.ToUpper(typ.kind)
+			if typ.unsigned {
+				result += " UNSIGNED"
+			}
+		case "float", "double":
+			result = strings.ToUpper(typ.kind)
+		case "decimal":
+			result = decimal("DECIMAL")
+		case "char":
+			result = sized("CHAR", typ.length, "CHAR(1)")
+		case "varchar":
+			result = sized("VARCHAR", typ.length, "VARCHAR(255)")
+		case "text":
+			result = "LONGTEXT"
+			// 与 gorm 的 MySQL 方言一致，主键、索引及带默认值的字符串字段不能使用 TEXT
+			if keyed || column.Default != nil {
+				result = "VARCHAR(191)"
+			}
+		case "date":
+			result = "DATE"
+		case "time":
+			result = precision("TIME")
+		case "datetime":
+			result = precision("DATETIME")
+		case "timestamptz":
+			result = precision("TIMESTAMP")
+		case "bytes":
+			result = sized("VARBINARY", typ.length, "LONGBLOB")
+		case "json":
+			result = "JSON"
+		case "uuid":
+			result = "CHAR(36)"
+		}
+	case gormx.DBTypePostgres, gormx.DBTypeGreenplum:
+		switch typ.kind {
+		case "bool":
+			result = "BOOLEAN"
+		case "tinyint", "smallint":
+			result = "SMALLINT"
+		case "int":
+			result = "INTEGER"
+		case "bigint":
+			result = "BIGINT"
+		case "float":
+			result = "REAL"
+		case "double":
+			result = "DOUBLE PRECISION"
+		case "decimal":
+			result = decimal("NUMERIC")
+		case "char":
+			result = sized("CHAR", typ.length, "CHAR(1)")
+		case "varchar":
+			result = sized("VARCHAR", typ.length, "VARCHAR")
+		case "text":
+			result = "TEXT"
+		case "date":
+			result = "DATE"
+		case "time":
+			result = precision("TIME")
+		case "datetime":
+			result = precision("TIMESTAMP")
+		case "timestamptz":
+			result = precision("TIMESTAMPTZ")
+		case "bytes":
+			result = "BYTEA"
+		case "json":
+			result = "JSONB"
+		case "uuid":
+			result = "UUID"
+		}
+		if typ.array {
+			result += "[]"
+		}
+	case gormx.DBTypeDM:
+		switch typ.kind {
+		case "bool":
+			result = "BIT"
+		case "tinyint", "smallint", "int", "bigint":
+			result = strings.ToUpper(typ.kind)
+		case "float":
+			result = "REAL"
+		case "double":
+			result = "DOUBLE"
+		case "decimal":
+			result = decimal("DECIMAL")
+		case "char":
+			result = sized("CHAR", typ.length, "CHAR(1)")
+		case "varchar":
+			result = sized("VARCHAR", typ.length, "VARCHAR(8188)")
+		case "text", "json":
+			result = "CLOB"
+		case "date":
+			result = "DATE"
+		case "time":
+			result = precision("TIME")
+		case "datetime":
+			result = precision("TIMESTAMP")
+		case "timestamptz":
+			result = precision("TIMESTAMP") + " WITH TIME ZONE"
+		case "bytes":
+			result = sized("VARBINARY", typ.length, "BLOB")
+		case "uuid":
+			result = "VARCHAR(36)"
+		}
+	case gormx.DBTypeSqlserver:
+		switch typ.kind {
+		case "bool":
+			result = "BIT"
+		case "tinyint":
+			result = "SMALLINT"
+			if typ.unsigned {
+				result = "TINYINT"
+			}
+		case "smallint", "int", "bigint":
+			result = strings.ToUpper(typ.kind)
+		case "float":
+			result = "REAL"
+		case "double":
+			result = "FLOAT"
+		case "decimal":
+			result = decimal("DECIMAL")
+		case "char":
+			result = sized("NCHAR", typ.length, "NCHAR(1)")
+		case "varchar":
+			result = sized("NVARCHAR", typ.length, "NVARCHAR(MAX)")
+		case "text", "json":
+			result = "NVARCHAR(MAX)"
+		case "date":
+			result = "DATE"
+		case "time":
+			result = precision("TIME")
+		case "datetime":
+			result = precision("DATETIME2")
+		case "timestamptz":
+			result = precision("DATETIMEOFFSET")
+		case "bytes":
+			result = sized("VARBINARY", typ.length, "VARBINARY(MAX)")
+		case "uuid":
+			result = "UNIQUEIDENTIFIER"
+		}
+	case gormx.DBTypeOracle:
+		switch typ.kind {
+		case "bool":
+			result = "NUMBER(1)"
+		case "tinyint":
+			result = "NUMBER(3)"
+		case "smallint":
+			result = "NUMBER(5)"
+		case "int":
+			result = "NUMBER(10)"
+		case "bigint":
+			result = "NUMBER(19)"
+		case "float":
+			result = "BINARY_FLOAT"
+		case "double":
+			result = "BINARY_DOUBLE"
+		case "decimal":
+			result = decimal("NUMBER")
+		case "char":
+			result = sized("CHAR", typ.length, "CHAR(1)")
+		case "varchar":
+			result = sized("VARCHAR2", typ.length, "VARCHAR2(4000)")
+		case "text", "json":
+			result = "CLOB"
+		case "date":
+			result = "DATE"
+		case "time", "datetime":
+			result = precision("TIMESTAMP")
+		case "timestamptz":
+			result = precision("TIMESTAMP") + " WITH TIME ZONE"
+		case "bytes":
+			result = sized("RAW", typ.length, "BLOB")
+		case "uuid":
+			result = "VARCHAR2(36)"
+		}
+	case gormx.DBTypeSQLite:
+		switch typ.kind {
+		case "bool", "tinyint", "smallint", "int", "bigint":
+			result = "INTEGER"
+		case "float", "double":
+			result = "REAL"
+		case "decimal":
+			result = "NUMERIC"
+		case "char", "varchar":
+			result = sized("VARCHAR", typ.length, "TEXT")
+		case "text", "json", "uuid":
+			result = "TEXT"
+		case "date":
+			result = "DATE"
+		case "time":
+			result = "TIME"
+		case "datetime", "timestamptz":
+			result = "DATETIME"
+		case "bytes":
+			result = "BLOB"
+		}
+	}
+	return result
+}
+
+// autoIncrement 自增字段的写法：PostgreSQL 使用 serial 类型，MySQL 使用 AUTO_INCREMENT，其余使用 IDENTITY
+func (w *ddlWriter) autoIncrement(table *SchemaTable, column *SchemaColumn, typ ddlType, columnType string) (string, string) {
+	integer := typ.kind == "tinyint" || typ.kind == "smallint" || typ.kind == "int" || typ.kind == "bigint"
+	if !integer {
+		w.warn(table, "auto increment column %s is not an integer, AUTO_INCREMENT dropped", column.Name)
+		return columnType, ""
+	}
+	switch w.dbType {
+	case gormx.DBTypeMySQL:
+		// MySQL 的自增字段必须是主键或索引的第一列
+		if !slices.ContainsFunc(table.Indexes, func(index *SchemaIndex) bool {
+			return len(index.Columns) > 0 && strings.EqualFold(index.Columns[0], column.Name)
+		}) {
+			w.warn(table, "auto increment column %s is not a key, AUTO_INCREMENT dropped", column.Name)
+			return columnType, ""
+		}
+		return columnType, "AUTO_INCREMENT"
+	case gormx.DBTypePostgres, gormx.DBTypeGreenplum:
+		switch {
+		case typ.kind == "tinyint", typ.kind == "smallint" && !typ.unsigned:
+			return "SMALLSERIAL", ""
+		case typ.kind == "smallint", typ.kind == "int" && !typ.unsigned:
+			return "SERIAL", ""
+		}
+		return "BIGSERIAL", ""
+	case gormx.DBTypeDM, gormx.DBTypeSqlserver:
+		return columnType + " IDENTITY(1,1)", ""
+	case gormx.DBTypeOracle:
+		return columnType + " GENERATED BY DEFAULT AS IDENTITY", ""
+	case gormx.DBTypeSQLite:
+		// SQLite 只有 INTEGER PRIMARY KEY 可以自增
+		if len(table.PrimaryKey) == 1 && strings.EqualFold(table.PrimaryKey[0], column.Name) {
+			return "INTEGER", "PRIMARY KEY AUTOINCREMENT"
+		}
+		w.warn(table, "auto increment column %s is not the only primary key, AUTOINCREMENT dropped", column.Name)
+	}
+	return columnType, ""
+}
+
+// sqlDefault 默认值的类别
+const (
+	defaultLiteral = iota // 字符串
+	defaultNumber
+	defaultBool
+	defaultNow  // 当前时间
+	defaultDate // 当前日期
+	defaultUUID
+	defaultRaw // 其他表达式
+)
+
+var (
+	numberPattern          = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+	mysqlExpressionDefault = regexp.MustCompile(`(?i)(text|blob|json|geometry)`)
+	functionPattern        = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(\(\s*\d*\s*\))?$`)
+)
+
+// defaultFunctions 各数据库的函数 -> 默认值类别
+var defaultFunctions = map[string]int{
+	"CURRENT_TIMESTAMP": defaultNow, "NOW": defaultNow, "LOCALTIMESTAMP": defaultNow, "SYSDATE": defaultNow,
+	"SYSTIMESTAMP": defaultNow, "GETDATE": defaultNow, "SYSDATETIME": defaultNow, "TRANSACTION_TIMESTAMP": defaultNow,
+	"STATEMENT_TIMESTAMP": defaultNow, "CLOCK_TIMESTAMP": defaultNow, "CURRENT_DATE": defaultDate, "CURDATE": defaultDate,
+	"UUID": defaultUUID, "GEN_RANDOM_UUID": defaultUUID, "UUID_GENERATE_V4": defaultUUID, "NEWID": defaultUUID,
+	"SYS_GUID": defaultUUID,
+}
+
+// castIndex 默认值中 PostgreSQL 类型转换 :: 的位置，不存在时返回 -1
+func castIndex(value string) int {
+	quoted := false
+	for i := 0; i < len(value)-1; i++ {
+		switch {
+		case value[i] == '\'':
+			quoted = !quoted
+		case !quoted && value[i] == ':' && value[i+1] == ':':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDefault 将默认值归类；MySQL 与 gorm 标签中的字符串默认值不带引号，quoted 表示字符串一定带引号
+func parseDefault(value string, typ ddlType, quoted bool) (int, string) {
+	value = strings.TrimSpace(value)
+	if i := castIndex(value); i > 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	for len(value) > 2 && value[0] == '(' && value[len(value)-1] == ')' && !strings.ContainsAny(value[1:len(value)-1], "()") {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	upper := strings.ToUpper(value)
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return defaultLiteral, strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	switch {
+	case numberPattern.MatchString(value):
+		return defaultNumber, value
+	case upper == "TRUE" || upper == "FALSE":
+		return defaultBool, upper
+	case upper == "B'1'" || upper == "B'0'":
+		return defaultNumber, value[2:3]
+	}
+	if match := functionPattern.FindStringSubmatch(upper); match != nil {
+		if kind, ok := defaultFunctions[match[1]]; ok && (match[2] != "" || kind == defaultNow || kind == defaultDate) {
+			return kind, value
+		}
+	}
+	switch typ.kind {
+	case "char", "varchar", "text", "json", "uuid", "date", "time", "datetime", "timestamptz":
+		if !quoted {
+			return defaultLiteral, value
+		}
+	}
+	return defaultRaw, value
+}
+
+// defaultValue 默认值在目标数据库中的写法，无默认值或无法转换时返回空串
+func (w *ddlWriter) defaultValue(table *SchemaTable, column *SchemaColumn, typ ddlType) string {
+	if column.Default == nil || strings.EqualFold(strings.TrimSpace(*column.Default), "NULL") {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToLower(*column.Default), "nextval(") {
+		return ""
+	}
+	kind, value := parseDefault(*column.Default, typ, w.source != "" && w.source != gormx.DBTypeMySQL)
+	// 与 gorm 的迁移一致，gorm 标签中 string 字段的默认值去掉引号后按字符串写出，含括号的视为表达式原样写出
+	if raw := strings.TrimSpace(*column.Default); w.source == "" && column.GoType == "string" && raw != "" {
+		switch {
+		case !strings.Contains(raw, "(") || !strings.Contains(raw, ")"):
+			kind, value = defaultLiteral, strings.Trim(strings.Trim(raw, "'"), `"`)
+		case kind == defaultLiteral && raw[0] != '\'':
+			kind = defaultRaw
+		}
+	}
+	if typ.kind == "bool" && kind == defaultNumber {
+		kind, value = defaultBool, strconv.FormatBool(value != "0")
+	}
+	switch kind {
+	case defaultLiteral:
+		if typ.kind == "bool" {
+			kind, value = defaultBool, strconv.FormatBool(value == "1" || strings.EqualFold(value, "true"))
+			break
+		}
+		return w.literal(value)
+	case defaultNumber:
+		return value
+	case defaultNow:
+		if w.dbType == gormx.DBTypeMySQL && typ.precision > 0 {
+			return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", typ.precision)
+		}
+		return "CURRENT_TIMESTAMP"
+	case defaultDate:
+		switch w.dbType {
+		case gormx.DBTypeMySQL:
+			return "(CURRENT_DATE)"
+		case gormx.DBTypeSqlserver:
+			return "CAST(GETDATE() AS DATE)"
+		}
+		return "CURRENT_DATE"
+	case defaultUUID:
+		switch w.dbType {
+		case gormx.DBTypeMySQL:
+			return "(UUID())"
+		case gormx.DBTypePostgres, gormx.DBTypeGreenplum:
+			return "gen_random_uuid()"
+		case gormx.DBTypeSqlserver:
+			return "NEWID()"
+		case gormx.DBTypeOracle, gormx.DBTypeDM:
+			return "SYS_GUID()"
+		}
+		w.warn(table, "column %s: default %s dropped", column.Name, value)
+		return ""
+	case defaultRaw:
+		// 来自同一数据库或 gorm 标签的表达式原样保留
+		if w.source == "" || sameDialect(w.source, w.dbType) {
+			return value
+		}
+		w.warn(table, "column %s: default expression %s is not translated", column.Name, value)
+		return ""
+	}
+	boolean := value == "true" || value == "TRUE"
+	if w.dbType == gormx.DBTypePostgres || w.dbType == gormx.DBTypeGreenplum {
+		return strings.ToUpper(strconv.FormatBool(boolean))
+	}
+	if boolean {
+		return "1"
+	}
+	return "0"
+}
+
+// ddlColumn 一个字段定义的各部分，用于对齐输出
+type ddlColumn struct {
+	name, typ, rest string
+}
+
+// writeTable 输出建表、索引及注释语句
+func (w *ddlWriter) writeTable(table *SchemaTable) {
+	tableName := w.tableName(table)
+	keyed := make(map[string]bool)
+	for _, name := range table.PrimaryKey {
+		keyed[strings.ToLower(name)] = true
+	}
+	for _, index := range table.Indexes {
+		for _, name := range index.Columns {
+			keyed[strings.ToLower(name)] = true
+		}
+	}
+
+	var (
+		columns     []ddlColumn
+		inlineKey   bool // SQLite 的自增主键写在字段上
+		hasComments bool
+	)
+	for _, column := range table.Columns {
+		var columnType string
+		typ, ok := w.columnType(column)
+		// 枚举、网络地址等类型按类型映射的结果作为字符串
+		stringType := !ok && column.ColumnType != "" && w.source != "" &&
+			metadata.GetMetaType(w.source, column.DatabaseType).GoType == "string"
+		if stringType {
+			typ = ddlType{kind: "text"}
+		}
+		switch {
+		case column.ColumnType != "" && sameDialect(w.source, w.dbType):
+			columnType = column.ColumnType
+		case ok:
+			columnType = w.renderType(table, column, typ, keyed[strings.ToLower(column.Name)] || column.Unique)
+		case stringType:
+			columnType = w.renderType(table, column, typ, keyed[strings.ToLower(column.Name)] || column.Unique)
+			w.warn(table, "column %s: type %s has no equivalent, written as %s", column.Name, column.ColumnType, columnType)
+		case column.ColumnType != "":
+			columnType = column.ColumnType
+			w.warn(table, "column %s: type %s is not translated, written as is", column.Name, column.ColumnType)
+		default:
+			columnType = w.renderType(table, column, ddlType{kind: "text"}, keyed[strings.ToLower(column.Name)])
+			w.warn(table, "column %s: cannot infer a column type from Go type %s, add a gorm type tag", column.Name, column.GoType)
+		}
+
+		var parts []string
+		var suffix string
+		if column.AutoIncrement {
+			columnType, suffix = w.autoIncrement(table, column, typ, columnType)
+			inlineKey = inlineKey || strings.HasPrefix(suffix, "PRIMARY KEY")
+		} else if value := w.defaultValue(table, column, typ); value != "" {
+			// MySQL 8.0.13 起 TEXT、BLOB、JSON 字段只允许表达式默认值
+			if w.dbType == gormx.DBTypeMySQL && strings.HasPrefix(value, "'") && mysqlExpressionDefault.MatchString(columnType) {
+				value = "(" + value + ")"
+			}
+			parts = append(parts, "DEFAULT "+value)
+		}
+		if !column.Nullable {
+			parts = append(parts, "NOT NULL")
+		}
+		if suffix != "" {
+			parts = append(parts, suffix)
+		}
+		if column.ReadOnly {
+			w.warn(table, "generated column %s is written as a regular column", column.Name)
+		}
+		if column.Comment != "" {
+			hasComments = true
+			if w.dbType == gormx.DBTypeMySQL {
+				parts = append(parts, "COMMENT "+w.literal(column.Comment))
+			}
+		}
+		columns = append(columns, ddlColumn{name: w.quote(column.Name), typ: columnType, rest: strings.Join(parts, " ")})
+	}
+
+	var constraints []string
+	if len(table.PrimaryKey) > 0 && !inlineKey {
+		constraints = append(constraints, fmt.Sprintf("PRIMARY KEY (%s)", w.columnList(table.PrimaryKey)))
+	}
+	// 字段上的唯一约束没有对应索引时单独写出
+	for _, column := range table.Columns {
+		if column.Unique && !column.PrimaryKey && !slices.ContainsFunc(table.Indexes, func(index *SchemaIndex) bool {
+			return index.Unique && len(index.Columns) == 1 && strings.EqualFold(index.Columns[0], column.Name)
+		}) {
+			constraints = append(constraints, fmt.Sprintf("UNIQUE (%s)", w.quote(column.Name)))
+		}
+	}
+	var indexes []*SchemaIndex
+	for _, index := range table.Indexes {
+		switch {
+		case index.PrimaryKey:
+		case w.dbType == gormx.DBTypeMySQL:
+			keyword := "KEY"
+			switch {
+			case index.Unique:
+				keyword = "UNIQUE KEY"
+			case index.Option == "FULLTEXT" || index.Option == "SPATIAL":
+				keyword = index.Option + " KEY"
+			}
+			constraints = append(constraints, fmt.Sprintf("%s %s (%s)", keyword, w.quote(index.Name), w.columnList(index.Columns)))
+		default:
+			indexes = append(indexes, index)
+		}
+	}
+
+	ifNotExists := ""
+	switch w.dbType {
+	case gormx.DBTypeMySQL, gormx.DBTypePostgres, gormx.DBTypeGreenplum, gormx.DBTypeSQLite:
+		ifNotExists = "IF NOT EXISTS "
+	}
+	fmt.Fprintf(&w.sb, "CREATE TABLE %s%s (\n", ifNotExists, tableName)
+	nameWidth, typeWidth := 0, 0
+	for _, column := range columns {
+		nameWidth, typeWidth = max(nameWidth, len(column.name)), max(typeWidth, len(column.typ))
+	}
+	for i, column := range columns {
+		line := fmt.Sprintf("    %-*s %s", nameWidth, column.name, column.typ)
+		if column.rest != "" {
+			line = fmt.Sprintf("    %-*s %-*s %s", nameWidth, column.name, typeWidth, column.typ, column.rest)
+		}
+		w.sb.WriteString(line)
+		if i < len(columns)-1 || len(constraints) > 0 {
+			w.sb.WriteByte(',')
+		}
+		w.sb.WriteByte('\n')
+	}
+	for i, constraint := range constraints {
+		w.sb.WriteString("    " + constraint)
+		if i < len(constraints)-1 {
+			w.sb.WriteByte(',')
+		}
+		w.sb.WriteByte('\n')
+	}
+	w.sb.WriteString(")")
+	if w.dbType == gormx.DBTypeMySQL && table.Comment != "" {
+		w.sb.WriteString(" COMMENT = " + w.literal(table.Comment))
+	}
+	w.sb.WriteString(";\n")
+
+	for _, index := range indexes {
+		w.writeIndex(table, tableName, index)
+	}
+	if w.dbType != gormx.DBTypeMySQL && (table.Comment != "" || hasComments) {
+		w.writeComments(table, tableName)
+	}
+	w.sb.WriteByte('\n')
+}
+
+// columnList 逗号分隔的字段列表
+func (w *ddlWriter) columnList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, w.quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// writeIndex 输出 CREATE INDEX；索引名在 schema 内重复时加表名前缀
+func (w *ddlWriter) writeIndex(table *SchemaTable, tableName string, index *SchemaIndex) {
+	name := index.Name
+	if name == "" || w.indexNames[strings.ToLower(table.Schema+"."+name)] {
+		name = table.Name + "_" + name
+	}
+	w.indexNames[strings.ToLower(table.Schema+"."+name)] = true
+	if index.Option != "" {
+		w.warn(table, "%s index %s is written as a regular index", index.Option, index.Name)
+	}
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	ifNotExists := ""
+	switch w.dbType {
+	case gormx.DBTypePostgres, gormx.DBTypeGreenplum, gormx.DBTypeSQLite:
+		ifNotExists = "IF NOT EXISTS "
+	}
+	fmt.Fprintf(&w.sb, "CREATE %sINDEX %s%s ON %s (%s);\n", unique, ifNotExists, w.quote(name), tableName, w.columnList(index.Columns))
+}
+
+// writeComments 输出表及字段注释：SQL Server 使用扩展属性，SQLite 不支持注释
+func (w *ddlWriter) writeComments(table *SchemaTable, tableName string) {
+	switch w.dbType {
+	case gormx.DBTypeSQLite:
+		w.warn(table, "sqlite does not support comments, comments dropped")
+	case gormx.DBTypeSqlserver:
+		schema := table.Schema
+		if schema == "" || schema == "public" {
+			schema = "dbo"
+		}
+		property := "EXEC sp_addextendedproperty 'MS_Description', %s, 'SCHEMA', %s, 'TABLE', %s%s;\n"
+		if table.Comment != "" {
+			fmt.Fprintf(&w.sb, property, w.literal(table.Comment), w.literal(schema), w.literal(table.Name), "")
+		}
+		for _, column := range table.Columns {
+			if column.Comment != "" {
+				fmt.Fprintf(&w.sb, property, w.literal(column.Comment), w.literal(schema), w.literal(table.Name),
+					", 'COLUMN', "+w.literal(column.Name))
+			}
+		}
+	default:
+		if table.Comment != "" {
+			fmt.Fprintf(&w.sb, "COMMENT ON TABLE %s IS %s;\n", tableName, w.literal(table.Comment))
+		}
+		for _, column := range table.Columns {
+			if column.Comment != "" {
+				fmt.Fprintf(&w.sb, "COMMENT ON COLUMN %s.%s IS %s;\n", tableName, w.quote(column.Name), w.literal(column.Comment))
+			}
+		}
+	}
+}
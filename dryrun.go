@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// diffContext unified diff 上下文行数
+const diffContext = 3
+
+// genReport dry-run 模式下各文件的变化情况
+var genReport struct {
+	Created   []string // 新建
+	Modified  []string // 内容变化
+	Unchanged []string // 内容一致
+	Skipped   []string // 未重新生成（表未变化或文件已存在且不覆盖）
+	Failed    []string // 读取表结构或渲染失败的表、文件
+	Pruned    []string // 已删除表遗留的生成文件
+}
+
+// ensureDir 创建输出目录，dry-run 时不落盘
+func ensureDir(dir string) {
+	if genOptions.DryRun {
+		return
+	}
+	_ = createDirectory(dir)
+}
+
+// writeGenerated 写出生成内容：内容未变化时不改写文件，dry-run 时只记录变化并按需输出 diff；written 表示文件实际被写入
+func writeGenerated(path string, content []byte, perm fs.FileMode) (written bool, err error) {
+	old, err := os.ReadFile(path)
+	exist := err == nil
+	if exist && bytes.Equal(old, content) {
+		genReport.Unchanged = append(genReport.Unchanged, path)
+		return false, nil
+	}
+	if genOptions.DryRun {
+		if exist {
+			genReport.Modified = append(genReport.Modified, path)
+		} else {
+			genReport.Created = append(genReport.Created, path)
+		}
+		if genOptions.Diff {
+			fmt.Print(unifiedDiff(displayPath(path), old, content, exist))
+		}
+		return false, nil
+	}
+	ensureDir(filepath.Dir(path))
+	if err = os.WriteFile(path, content, perm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// displayPath 输出时使用相对当前目录的路径
+func displayPath(path string) string {
+	wd, _ := os.Getwd()
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// printGenReport 输出 dry-run 汇总
+func printGenReport() {
+	sections := []struct {
+		title string
+		files []string
+	}{
+		{"created", genReport.Created},
+		{"modified", genReport.Modified},
+		{"unchanged", genReport.Unchanged},
+		{"skipped", genReport.Skipped},
+		{"pruned", genReport.Pruned},
+	}
+	fmt.Println("dry-run summary:")
+	for _, section := range sections {
+		// 公共文件（如 base.go、db.go）会被多张表重复渲染
+		slices.Sort(section.files)
+		section.files = slices.Compact(section.files)
+		fmt.Printf("  %-9s %d\n", section.title, len(section.files))
+		if section.title == "unchanged" {
+			continue
+		}
+		for _, file := range section.files {
+			fmt.Printf("    %s\n", displayPath(file))
+		}
+	}
+}
+
+// splitLines 按行拆分，保留行尾换行符
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp 编辑脚本中的一行：' ' 相同，'-' 删除，'+' 新增
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines 基于最长公共子序列计算两组行之间的编辑脚本
+func diffLines(a, b []string) []diffOp {
+	// 去掉首尾相同的部分，缩小动态规划的规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(midA) && j < len(midB) {
+		switch {
+		case midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+	for ; i < len(midA); i++ {
+		ops = append(ops, diffOp{'-', midA[i]})
+	}
+	for ; j < len(midB); j++ {
+		ops = append(ops, diffOp{'+', midB[j]})
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// unifiedDiff 生成 unified diff 格式的差异，exist 为 false 时与 /dev/null 比较
+func unifiedDiff(name string, old, new []byte, exist bool) string {
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var sb strings.Builder
+	if exist {
+		sb.WriteString("--- a/" + name + "\n")
+	} else {
+		sb.WriteString("--- /dev/null\n")
+	}
+	sb.WriteString("+++ b/" + name + "\n")
+
+	for start := 0; start < len(ops); {
+		// 定位下一处变化
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// 合并上下文重叠的变化为一个 hunk
+		begin := max(start-diffContext, 0)
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		end = min(end+diffContext, len(ops))
+
+		oldStart, newStart := 1, 1
+		for _, op := range ops[:begin] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[begin:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[begin:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// processCheck check 子命令：去掉子命令名后按默认生成模式解析参数
+func processCheck() {
+	os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	genOptions.Check = true
+	processDB()
+}
+
+// checkGenReport 输出校验结果，存在缺失、过期的生成文件或生成失败时以状态码 1 退出
+func checkGenReport() {
+	slices.Sort(genReport.Created)
+	slices.Sort(genReport.Modified)
+	slices.Sort(genReport.Failed)
+	stale := len(genReport.Created) + len(genReport.Modified)
+	if stale == 0 && len(genReport.Failed) == 0 {
+		fmt.Printf("generated code is up to date (%d files)\n", len(slices.Compact(genReport.Unchanged)))
+		return
+	}
+	for _, file := range slices.Compact(genReport.Created) {
+		fmt.Printf("missing  %s\n", displayPath(file))
+	}
+	for _, file := range slices.Compact(genReport.Modified) {
+		fmt.Printf("stale    %s\n", displayPath(file))
+	}
+	for _, item := range slices.Compact(genReport.Failed) {
+		fmt.Printf("failed   %s\n", displayPath(item))
+	}
+	if stale > 0 {
+		fmt.Println("generated code is out of date, run gentol to regenerate")
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// resetGenState 还原测试修改的生成参数、变化汇总及本表文件记录
+func resetGenState(t *testing.T) {
+	t.Helper()
+	options, report, files := genOptions, genReport, renderedFiles
+	t.Cleanup(func() { genOptions, genReport, renderedFiles = options, report, files })
+	genReport.Created, genReport.Modified, genReport.Unchanged = nil, nil, nil
+	renderedFiles = nil
+}
+
+func TestWriteGenerated(t *testing.T) {
+	resetGenState(t)
+	path := filepath.Join(t.TempDir(), "sub", "a.txt")
+
+	if written, err := writeGenerated(path, []byte("a\n"), 0644); err != nil || !written {
+		t.Fatalf("first write = %v, %v, want written", written, err)
+	}
+	if written, err := writeGenerated(path, []byte("a\n"), 0644); err != nil || written {
+		t.Fatalf("unchanged write = %v, %v, want skipped", written, err)
+	}
+	if len(genReport.Unchanged) != 1 {
+		t.Errorf("unchanged = %q, want %s", genReport.Unchanged, path)
+	}
+
+	genOptions.DryRun = true
+	if written, err := writeGenerated(path, []byte("b\n"), 0644); err != nil || written {
+		t.Fatalf("dry-run write = %v, %v, want nothing written", written, err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "a\n" {
+		t.Errorf("dry-run changed the file to %q", content)
+	}
+	if len(genReport.Modified) != 1 {
+		t.Errorf("modified = %q, want %s", genReport.Modified, path)
+	}
+}
+
+// TestWriteRenderedLogsWrites 只有实际写入的文件才输出 writing 日志，公共文件重复渲染时不重复输出
+func TestWriteRenderedLogsWrites(t *testing.T) {
+	resetGenState(t)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	tpl := &metadata.Template{Name: "base", Content: "base\n"}
+	path := filepath.Join(t.TempDir(), "base.txt")
+	for i := 0; i < 3; i++ {
+		if err := writeRendered(tpl, []byte("base\n"), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Count(buf.String(), "writing "+path); got != 1 {
+		t.Errorf("writing logged %d times, want once:\n%s", got, buf.String())
+	}
+	// 内容未变化的文件仍属于本表的生成文件
+	if len(renderedFiles) != 3 {
+		t.Errorf("renderedFiles = %q, want the file recorded for every render", renderedFiles)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pborman/getopt/v2"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm/logger"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// inspectVersion inspect 输出格式版本，字段不兼容变更时递增
+const inspectVersion = 1
+
+// InspectResult inspect 输出的完整表结构
+type InspectResult struct {
+	Version   int                `json:"version" yaml:"version"`
+	Databases []*InspectDatabase `json:"databases" yaml:"databases"`
+}
+
+// InspectDatabase 单个库的表结构
+type InspectDatabase struct {
+	DBName  string           `json:"db_name" yaml:"db_name"`
+	DBType  string           `json:"db_type" yaml:"db_type"`
+	Schemas []*InspectSchema `json:"schemas" yaml:"schemas"`
+}
+
+// InspectSchema 单个 schema 的表结构，不区分 schema 的库 name 为空
+type InspectSchema struct {
+	Name   string         `json:"name" yaml:"name"`
+	Tables []*SchemaTable `json:"tables" yaml:"tables"`
+}
+
+// processInspect inspect 子命令：按默认生成模式的参数连接数据库，输出读取到的表结构
+func processInspect() {
+	os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	format := getopt.StringLong("format", 0, "json", "inspect output format [json | yaml]")
+	output := getopt.StringLong("output", 'o', "", "write the inspect result to file instead of stdout")
+	argHandler()
+	// 结构输出到 stdout，gorm 日志改写到 stderr
+	logger.Default = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+		Colorful:      true,
+	})
+
+	// 未指定格式时按输出文件扩展名推断
+	if !getopt.IsSet("format") {
+		if ext := filepath.Ext(*output); ext == ".yaml" || ext == ".yml" {
+			*format = "yaml"
+		}
+	}
+
+	result := &InspectResult{Version: inspectVersion}
+	for _, dbInfo := range configx.TableConfigs.Configs {
+		result.Databases = append(result.Databases, inspectDatabase(dbInfo))
+	}
+	content, err := marshalInspect(result, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		os.Stdout.Write(content)
+		return
+	}
+	if err = os.WriteFile(*output, content, 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("writing %s", *output)
+}
+
+// inspectDatabase 读取库中满足表配置的全部表，分表不合并
+func inspectDatabase(dbInfo *configx.DBTableInfo) *InspectDatabase {
+	source := newSchemaSource(dbInfo)
+	if err := dbInfo.Naming.Compile(); err != nil {
+		panic(err)
+	}
+	tableMap := buildTableMap(dbInfo, source)
+	comments := loadTableComments(source)
+
+	database := &InspectDatabase{
+		DBName:  dbInfo.DBName,
+		DBType:  dbInfo.DBType,
+		Schemas: make([]*InspectSchema, 0, len(tableMap)),
+	}
+	for _, schema := range sortedKeys(tableMap) {
+		inspectSchema := &InspectSchema{Name: schema, Tables: make([]*SchemaTable, 0, len(tableMap[schema]))}
+		for _, tableName := range sortedKeys(tableMap[schema]) {
+			columnTypes, indexes, readOnlyColumns, err := source.table(schema, tableName)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			inspectSchema.Tables = append(inspectSchema.Tables, newSchemaTable(dbInfo, schema, tableName, tableName,
+				comments[schema][tableName], columnTypes, indexes, readOnlyColumns))
+		}
+		database.Schemas = append(database.Schemas, inspectSchema)
+	}
+	return database
+}
+
+// marshalInspect 按格式序列化 inspect 结果
+func marshalInspect(result *InspectResult, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	case "yaml", "yml":
+		return yaml.Marshal(result)
+	}
+	return nil, fmt.Errorf("unsupported inspect format %q, use json or yaml", format)
+}
+
+// sortedKeys 返回按字典序排序的 map 键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// TestInspectJSONWithUnknownType 未知列类型的告警不能混入 stdout，inspect 输出必须是合法的 JSON
+func TestInspectJSONWithUnknownType(t *testing.T) {
+	dir := t.TempDir()
+	sqlFile := filepath.Join(dir, "a.sql")
+	writeTestFile(t, sqlFile, "CREATE TABLE place (\n  id BIGINT PRIMARY KEY,\n  location GEOMETRY NOT NULL\n);\n")
+
+	sqlPath := genOptions.SQLPath
+	genOptions.SQLPath = sqlFile
+	defer func() { genOptions.SQLPath = sqlPath }()
+
+	// 捕获表结构读取及输出期间写到 stdout 的全部内容
+	stdoutFile := filepath.Join(dir, "stdout")
+	captured, err := os.Create(stdoutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = captured
+	func() {
+		defer func() { os.Stdout = stdout }()
+		dbInfo := &configx.DBTableInfo{DBName: "test", DBType: "mysql"}
+		result := &InspectResult{Version: inspectVersion, Databases: []*InspectDatabase{inspectDatabase(dbInfo)}}
+		content, err := marshalInspect(result, "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout.Write(content)
+	}()
+	captured.Close()
+
+	content, err := os.ReadFile(stdoutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &InspectResult{}
+	if err = json.Unmarshal(content, result); err != nil {
+		t.Fatalf("inspect output is not valid JSON: %v\n%s", err, content)
+	}
+	if len(result.Databases) != 1 || len(result.Databases[0].Schemas) != 1 ||
+		len(result.Databases[0].Schemas[0].Tables) != 1 {
+		t.Fatalf("inspect result = %s, want the place table only", content)
+	}
+	table := result.Databases[0].Schemas[0].Tables[0]
+	if table.Name != "place" || len(table.Columns) != 2 || table.Columns[1].Name != "location" {
+		t.Errorf("inspect table = %+v, want place with id and location", table)
+	}
+}
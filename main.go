@@ -53,7 +53,46 @@ func main() {
 		// 项目更新
 		templateRepo, templateDir, templateBranch := getTemplateFlags()
 		updateProject(getProjectName(), templateRepo, templateDir, templateBranch)
+	case "check":
+		if hasHelpFlag(os.Args[2:]) {
+			printSubUsage(checkUsage)
+			return
+		}
+		// 校验生成代码是否最新
+		processCheck()
+	case "inspect":
+		if hasHelpFlag(os.Args[2:]) {
+			printSubUsage(inspectUsage)
+			return
+		}
+		// 输出读取到的表结构
+		processInspect()
+	case "templates":
+		if len(os.Args) < 3 || os.Args[2] != "export" || hasHelpFlag(os.Args[3:]) {
+			printSubUsage(templatesUsage)
+			return
+		}
+		// 导出内置模板
+		processTemplatesExport()
 	case "ddl":
+		if len(os.Args) > 2 && os.Args[2] == "export" {
+			if hasHelpFlag(os.Args[3:]) {
+				printSubUsage(ddlExportUsage)
+				return
+			}
+			// 从 gorm 模型导出建表语句
+			processDDLExport()
+			return
+		}
+		if len(os.Args) > 2 && os.Args[2] == "translate" {
+			if hasHelpFlag(os.Args[3:]) {
+				printSubUsage(ddlTranslateUsage)
+				return
+			}
+			// 将建表语句翻译为其他数据库的写法
+			processDDLTranslate()
+			return
+		}
 		if hasHelpFlag(os.Args[2:]) {
 			printSubUsage(ddlUsage)
 			return
@@ -65,6 +104,21 @@ func main() {
 	}
 }
 
+// processTemplatesExport templates export 子命令
+func processTemplatesExport() {
+	dir, force := "templates", false
+	for _, arg := range os.Args[3:] {
+		if arg == "--force" {
+			force = true
+		} else if !strings.HasPrefix(arg, "-") {
+			dir = arg
+		}
+	}
+	if err := exportTemplates(dir, force); err != nil {
+		log.Fatalf("导出模板失败: %v", err)
+	}
+}
+
 // getProjectName 获取并验证项目名称（跳过 -- 开头的标志参数）
 func getProjectName() string {
 	projectName := ""
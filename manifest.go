@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// manifestPath 生成清单位置，记录每张表的输入指纹及生成的文件，用于增量生成
+const manifestPath = ".gentol/manifest.json"
+
+// Manifest 生成清单
+type Manifest struct {
+	Tables map[string]*ManifestEntry `json:"tables"`         // 库名:schema.表名 -> 生成记录
+	Once   []string                  `json:"once,omitempty"` // 覆盖策略为 once 的自定义输出中已生成过的文件
+}
+
+// ManifestEntry 单表生成记录
+type ManifestEntry struct {
+	Hash  string            `json:"hash"`           // 表结构、模板及配置的指纹
+	Files []string          `json:"files"`          // 本表生成的文件（相对当前目录）
+	Sums  map[string]string `json:"sums,omitempty"` // 文件 -> 生成时内容的 sha256，用于发现手工修改
+}
+
+var manifest = &Manifest{Tables: map[string]*ManifestEntry{}}
+
+// loadManifest 读取生成清单，不存在或损坏时从空清单开始
+func loadManifest() {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return
+	}
+	loaded := &Manifest{}
+	if err = json.Unmarshal(content, loaded); err != nil {
+		log.Printf("manifest %s is invalid, regenerate all tables: %v", manifestPath, err)
+		return
+	}
+	if loaded.Tables == nil {
+		loaded.Tables = map[string]*ManifestEntry{}
+	}
+	manifest = loaded
+}
+
+// saveManifest 写回生成清单
+func saveManifest() {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Printf("marshal manifest failed: %v", err)
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(manifestPath), 0755)
+	if err = os.WriteFile(manifestPath, append(content, '\n'), 0644); err != nil {
+		log.Printf("write manifest %s failed: %v", manifestPath, err)
+	}
+}
+
+// manifestKey 清单中表的键
+func manifestKey(dbInfo *configx.DBTableInfo, schema, tableName string) string {
+	return dbInfo.DBName + ":" + buildFullTableName(schema, tableName)
+}
+
+// upToDate 判断表的输入是否与上次生成时一致，且生成文件仍然存在、内容未被修改
+func (m *Manifest) upToDate(key, hash string) bool {
+	entry, ok := m.Tables[key]
+	if !ok || entry.Hash != hash || len(entry.Files) == 0 {
+		return false
+	}
+	for _, file := range entry.Files {
+		sum, ok := fileSum(file)
+		if !ok || sum != entry.Sums[file] {
+			return false
+		}
+	}
+	return true
+}
+
+// record 记录表本次生成的指纹、文件及文件内容摘要
+func (m *Manifest) record(key, hash string, files []string) {
+	relFiles := make([]string, 0, len(files))
+	sums := make(map[string]string, len(files))
+	for _, file := range files {
+		rel := relPath(file)
+		relFiles = append(relFiles, rel)
+		if sum, ok := fileSum(file); ok {
+			sums[rel] = sum
+		}
+	}
+	sort.Strings(relFiles)
+	m.Tables[key] = &ManifestEntry{Hash: hash, Files: relFiles, Sums: sums}
+}
+
+// addFiles 向表的生成记录追加文件（如插件为表生成的文件），保留原有指纹
+func (m *Manifest) addFiles(key string, files []string) {
+	entry, ok := m.Tables[key]
+	if !ok {
+		entry = &ManifestEntry{}
+		m.Tables[key] = entry
+	}
+	if entry.Sums == nil {
+		entry.Sums = make(map[string]string, len(files))
+	}
+	for _, file := range files {
+		rel := relPath(file)
+		if !slices.Contains(entry.Files, rel) {
+			entry.Files = append(entry.Files, rel)
+		}
+		if sum, ok := fileSum(file); ok {
+			entry.Sums[rel] = sum
+		}
+	}
+	sort.Strings(entry.Files)
+}
+
+// fileSum 文件内容的 sha256，文件不存在时返回 false
+func fileSum(file string) (string, bool) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// generatedOnce 判断 once 策略的文件是否已生成过
+func (m *Manifest) generatedOnce(file string) bool {
+	return slices.Contains(m.Once, relPath(file))
+}
+
+// markOnce 记录 once 策略的文件已生成
+func (m *Manifest) markOnce(file string) {
+	if !m.generatedOnce(file) {
+		m.Once = append(m.Once, relPath(file))
+		sort.Strings(m.Once)
+	}
+}
+
+// relPath 清单中的文件路径相对当前目录记录
+func relPath(file string) string {
+	wd, _ := os.Getwd()
+	if rel, err := filepath.Rel(wd, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
+
+// tableFingerprint 计算表的输入指纹：表结构、索引、注释、只读字段、模板内容及生成配置
+func tableFingerprint(dbInfo *configx.DBTableInfo, schema, tableName, sourceTable, tableComment string,
+	columnTypes []gorm.ColumnType, indexes []gorm.Index, readOnlyColumns []string) string {
+	columnInfoList := make([]*metadata.ColumnInfo, 0, len(columnTypes))
+	getColumnInfo(columnTypes, &columnInfoList)
+
+	type indexInfo struct {
+		Name       string
+		Columns    []string
+		Unique     bool
+		PrimaryKey bool
+		Option     string
+	}
+	indexInfoList := make([]indexInfo, 0, len(indexes))
+	for _, index := range indexes {
+		unique, _ := index.Unique()
+		primaryKey, _ := index.PrimaryKey()
+		indexInfoList = append(indexInfoList, indexInfo{
+			Name:       index.Name(),
+			Columns:    index.Columns(),
+			Unique:     unique,
+			PrimaryKey: primaryKey,
+			Option:     index.Option(),
+		})
+	}
+
+	globalConfig := *configx.TableConfigs
+	globalConfig.Configs = nil
+	// 插件每次都会执行，其参数不影响表的生成结果
+	globalConfig.Plugins = nil
+	content, _ := json.Marshal(map[string]any{
+		"schema":    schema,
+		"table":     tableName,
+		"source":    sourceTable,
+		"comment":   tableComment,
+		"columns":   columnInfoList,
+		"indexes":   indexInfoList,
+		"read_only": readOnlyColumns,
+		"templates": templatesFingerprint(),
+		"db_config": tableConfig(dbInfo, tableName),
+		"config":    globalConfig,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// tableConfig 库配置中影响单表生成结果的部分；表清单、过滤规则及其他表的审计、分表、命名配置不计入，
+// 增减其他表不会使本表重新生成
+func tableConfig(dbInfo *configx.DBTableInfo, tableName string) map[string]any {
+	shardFormat := ""
+	if shard := dbInfo.GetShard(tableName); shard != nil {
+		shardFormat = shard.GetFormat()
+	}
+	var abbreviations []string
+	if dbInfo.Naming != nil {
+		abbreviations = dbInfo.Naming.Abbreviations
+	}
+	structName := dbInfo.Naming.StructName(tableName)
+	return map[string]any{
+		"db_name":          dbInfo.DBName,
+		"db_type":          dbInfo.DBType,
+		"only_model":       dbInfo.OnlyModel,
+		"gen_hook":         dbInfo.GenHook,
+		"model_path":       dbInfo.ModelPath,
+		"dao_path":         dbInfo.DaoPath,
+		"model_module":     dbInfo.ModelModule,
+		"dao_module":       dbInfo.DaoModule,
+		"use_sql_nullable": dbInfo.UseSQLNullable,
+		"read_write_split": dbInfo.ReadWriteSplit,
+		"use_otel":         dbInfo.UseOtel,
+		"tenant_mode":      dbInfo.TenantMode,
+		"audited":          dbInfo.Audit.IsAudited(tableName),
+		"audit_table":      dbInfo.Audit.GetTableName(),
+		"sharding":         len(dbInfo.Shards) > 0,
+		"shard_format":     shardFormat,
+		"struct_name":      structName,
+		"file_name":        dbInfo.Naming.FileName(tableName, structName),
+		"abbreviations":    abbreviations,
+	}
+}
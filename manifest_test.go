@@ -0,0 +1,222 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// chdirTemp 切换到临时目录，清单中的文件路径相对当前目录记录
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+// resetManifest 以空的生成清单、已处理表开始，测试结束后还原
+func resetManifest(t *testing.T) {
+	t.Helper()
+	resetGenState(t)
+	savedManifest, savedSeen := manifest, seenTables
+	t.Cleanup(func() { manifest, seenTables = savedManifest, savedSeen })
+	manifest = &Manifest{Tables: map[string]*ManifestEntry{}}
+	seenTables = map[string]bool{}
+}
+
+func TestManifestUpToDate(t *testing.T) {
+	dir := chdirTemp(t)
+	model := filepath.Join(dir, "model", "user.go")
+	dao := filepath.Join(dir, "dao", "user_dao.go")
+	writeTestFile(t, model, "package model\n")
+	writeTestFile(t, dao, "package dao\n")
+
+	m := &Manifest{Tables: map[string]*ManifestEntry{}}
+	m.record("db:user", "hash", []string{model, dao})
+	if got := m.Tables["db:user"].Files; len(got) != 2 || got[0] != "dao/user_dao.go" || got[1] != "model/user.go" {
+		t.Fatalf("recorded files = %q", got)
+	}
+	if !m.upToDate("db:user", "hash") {
+		t.Fatal("freshly recorded table should be up to date")
+	}
+	if m.upToDate("db:user", "other") {
+		t.Error("changed fingerprint should not be up to date")
+	}
+	if m.upToDate("db:order", "hash") {
+		t.Error("unknown table should not be up to date")
+	}
+
+	// 手工修改生成文件后需要重新渲染
+	writeTestFile(t, model, "package model\n\n// edited\n")
+	if m.upToDate("db:user", "hash") {
+		t.Error("hand-edited file should not be up to date")
+	}
+	m.record("db:user", "hash", []string{model, dao})
+	if !m.upToDate("db:user", "hash") {
+		t.Error("re-recorded table should be up to date")
+	}
+
+	if err := os.Remove(dao); err != nil {
+		t.Fatal(err)
+	}
+	if m.upToDate("db:user", "hash") {
+		t.Error("deleted file should not be up to date")
+	}
+
+	// 旧版本的清单没有文件摘要，需要重新渲染一次
+	writeTestFile(t, dao, "package dao\n")
+	m.Tables["db:user"].Sums = nil
+	if m.upToDate("db:user", "hash") {
+		t.Error("entry without sums should not be up to date")
+	}
+}
+
+// fingerprintInput 计算指纹所需的全部输入，各用例在副本上修改一项
+type fingerprintInput struct {
+	dbInfo   configx.DBTableInfo
+	comment  string
+	columns  []migrator.ColumnType
+	indexes  []gorm.Index
+	readOnly []string
+}
+
+func (in fingerprintInput) fingerprint() string {
+	columnTypes := make([]gorm.ColumnType, 0, len(in.columns))
+	for _, column := range in.columns {
+		columnTypes = append(columnTypes, column)
+	}
+	return tableFingerprint(&in.dbInfo, "public", "user", "user", in.comment, columnTypes, in.indexes, in.readOnly)
+}
+
+func fingerprintColumn(name, columnType string, nullable bool, comment string) migrator.ColumnType {
+	return migrator.ColumnType{
+		NameValue:          sql.NullString{String: name, Valid: true},
+		DataTypeValue:      sql.NullString{String: columnType, Valid: true},
+		ColumnTypeValue:    sql.NullString{String: columnType, Valid: true},
+		PrimaryKeyValue:    sql.NullBool{Bool: name == "id", Valid: true},
+		UniqueValue:        sql.NullBool{Valid: true},
+		AutoIncrementValue: sql.NullBool{Valid: true},
+		LengthValue:        sql.NullInt64{Valid: true},
+		DecimalSizeValue:   sql.NullInt64{Valid: true},
+		ScaleValue:         sql.NullInt64{Valid: true},
+		NullableValue:      sql.NullBool{Bool: nullable, Valid: true},
+		ScanTypeValue:      reflect.TypeOf(""),
+		CommentValue:       sql.NullString{String: comment, Valid: true},
+	}
+}
+
+func TestTableFingerprint(t *testing.T) {
+	saved := *configx.TableConfigs
+	t.Cleanup(func() { *configx.TableConfigs = saved })
+
+	base := func() fingerprintInput {
+		return fingerprintInput{
+			dbInfo:  configx.DBTableInfo{DBName: "app", DBType: "postgres", ModelPath: "dal/model"},
+			comment: "用户",
+			columns: []migrator.ColumnType{
+				fingerprintColumn("id", "bigint", false, ""),
+				fingerprintColumn("name", "varchar", true, "名称"),
+			},
+			indexes: []gorm.Index{
+				migrator.Index{NameValue: "user_pkey", ColumnList: []string{"id"}, PrimaryKeyValue: sql.NullBool{Bool: true, Valid: true}},
+			},
+		}
+	}
+	want := base().fingerprint()
+	if again := base().fingerprint(); again != want {
+		t.Fatalf("fingerprint is not stable: %s != %s", again, want)
+	}
+
+	changes := []struct {
+		name   string
+		change func(in *fingerprintInput)
+	}{
+		{"table comment", func(in *fingerprintInput) { in.comment = "用户表" }},
+		{"column comment", func(in *fingerprintInput) { in.columns[1] = fingerprintColumn("name", "varchar", true, "姓名") }},
+		{"column type", func(in *fingerprintInput) { in.columns[1] = fingerprintColumn("name", "text", true, "名称") }},
+		{"column nullable", func(in *fingerprintInput) { in.columns[1] = fingerprintColumn("name", "varchar", false, "名称") }},
+		{"column added", func(in *fingerprintInput) {
+			in.columns = append(in.columns, fingerprintColumn("age", "integer", true, ""))
+		}},
+		{"index", func(in *fingerprintInput) {
+			in.indexes = append(in.indexes, migrator.Index{NameValue: "idx_name", ColumnList: []string{"name"}})
+		}},
+		{"read only column", func(in *fingerprintInput) { in.readOnly = []string{"name"} }},
+		{"db config", func(in *fingerprintInput) { in.dbInfo.UseSQLNullable = true }},
+		{"audit of the table", func(in *fingerprintInput) { in.dbInfo.Audit = &configx.AuditInfo{Tables: []string{"user"}} }},
+		{"struct name of the table", func(in *fingerprintInput) {
+			in.dbInfo.Naming = &configx.NamingInfo{StructNames: map[string]string{"user": "Account"}}
+		}},
+		{"shard of the table", func(in *fingerprintInput) {
+			in.dbInfo.Shards = []*configx.ShardInfo{{TableName: "user", Pattern: `^user_\d+$`}}
+		}},
+	}
+	for _, tt := range changes {
+		in := base()
+		tt.change(&in)
+		if in.fingerprint() == want {
+			t.Errorf("changing the %s should change the fingerprint", tt.name)
+		}
+	}
+
+	// 其他表的增减、过滤规则及配置不影响本表，增量生成时不会重新生成本表
+	unrelated := []struct {
+		name   string
+		change func(in *fingerprintInput)
+	}{
+		{"table list", func(in *fingerprintInput) {
+			in.dbInfo.Tables = []*configx.TableInfo{{SchemaName: "public", TableList: []string{"user", "order"}}}
+		}},
+		{"exclude", func(in *fingerprintInput) {
+			in.dbInfo.Tables = []*configx.TableInfo{{SchemaName: "public", Exclude: []string{"*_bak"}}}
+		}},
+		{"audit of another table", func(in *fingerprintInput) { in.dbInfo.Audit = &configx.AuditInfo{Tables: []string{"order"}} }},
+		{"struct name of another table", func(in *fingerprintInput) {
+			in.dbInfo.Naming = &configx.NamingInfo{StructNames: map[string]string{"order": "Purchase"}}
+		}},
+		{"connection", func(in *fingerprintInput) { in.dbInfo.DSN, in.dbInfo.Password = "postgres://db", "secret" }},
+	}
+	for _, tt := range unrelated {
+		in := base()
+		tt.change(&in)
+		if in.fingerprint() != want {
+			t.Errorf("changing the %s should not change the fingerprint", tt.name)
+		}
+	}
+
+	// 全局配置
+	configx.TableConfigs.JsonFormat = "lower_camel"
+	if base().fingerprint() == want {
+		t.Error("changing the global config should change the fingerprint")
+	}
+	configx.TableConfigs.JsonFormat = ""
+
+	// 插件参数不影响生成结果
+	configx.TableConfigs.Plugins = []*configx.PluginInfo{{Name: "openapi", Options: map[string]any{"out": "api.yaml"}}}
+	if got := base().fingerprint(); got != want {
+		t.Error("plugins should not change the fingerprint")
+	}
+	configx.TableConfigs.Plugins = nil
+
+	// template_dir 中覆盖的模板
+	configx.TableConfigs.TemplateDir = t.TempDir()
+	withTemplateDir := base().fingerprint()
+	writeTestFile(t, filepath.Join(configx.TableConfigs.TemplateDir, "model"+tplExt), "package {{.ModelPackageName}}\n")
+	if base().fingerprint() == withTemplateDir {
+		t.Error("overriding a template should change the fingerprint")
+	}
+}
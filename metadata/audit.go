@@ -0,0 +1,316 @@
+package metadata
+
+// DefaultAuditTableName 默认审计日志表名
+const DefaultAuditTableName = "audit_log"
+
+// ModelAudit 审计日志模型及审计钩子使用的公共方法，每次生成都会覆盖
+const ModelAudit = NotEditMark + `
+package {{.ModelPackageName}}
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+)
+
+// auditBeforeImageKey 前镜像在 Statement.Settings 中的键
+const auditBeforeImageKey = "gentol:audit_before"
+
+// AuditTableKey DAO 在 Statement.Settings 中记录的实际访问表（多租户、分表路由后的表），审计日志按此记录表名
+const AuditTableKey = "gentol:audit_table"
+
+// auditActorCtxKey 审计操作人的上下文键
+type auditActorCtxKey struct{}
+
+// WithAuditActor 在上下文中设置审计操作人，DAO 方法会通过 WithContext 透传给钩子
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorCtxKey{}, actor)
+}
+
+// AuditActorFromContext 返回上下文中的审计操作人
+func AuditActorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(auditActorCtxKey{}).(string)
+	return actor
+}
+
+// {{.AuditStructName}} struct is mapping to the {{.AuditTableName}} table, records before/after images of audited tables
+type {{.AuditStructName}} struct {
+	ID          int64     ` + "`" + `gorm:"primaryKey;autoIncrement;column:id;not null" json:"id"` + "`" + `
+	TargetTable string    ` + "`" + `gorm:"column:table_name;not null;type:varchar(128)" json:"table_name"` + "`" + `
+	Operation   string    ` + "`" + `gorm:"column:operation;not null;type:varchar(16)" json:"operation"` + "`" + `
+	RecordKey   string    ` + "`" + `gorm:"column:record_key" json:"record_key"` + "`" + `
+	Actor       string    ` + "`" + `gorm:"column:actor" json:"actor"` + "`" + `
+	BeforeData  string    ` + "`" + `gorm:"column:before_data" json:"before_data"` + "`" + `
+	AfterData   string    ` + "`" + `gorm:"column:after_data" json:"after_data"` + "`" + `
+	CreatedAt   time.Time ` + "`" + `gorm:"column:created_at;not null" json:"created_at"` + "`" + `
+}
+
+func (a *{{.AuditStructName}}) TableName() string {
+	return "{{.AuditTableName}}"
+}
+
+// auditChange 单条记录的前后镜像
+type auditChange[T any] struct {
+	before *T
+	after  *T
+}
+
+// AuditBeforeChange 在更新/删除前按语句条件及主键查询受影响记录，保存为前镜像
+func AuditBeforeChange[T any](tx *gorm.DB, record *T) error {
+	exprs := auditPrimaryKeyExprs(tx, record)
+	if where, ok := tx.Statement.Clauses["WHERE"]; ok && where.Expression != nil {
+		exprs = append(exprs, where.Expression)
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+	var before []*T
+	if err := auditTableSession(tx).Model(new(T)).Clauses(exprs...).Find(&before).Error; err != nil {
+		return err
+	}
+	auditBeforeImages(tx).Store(record, before)
+	return nil
+}
+
+// AuditAfterCreate 新增后写入审计日志
+func AuditAfterCreate[T any](tx *gorm.DB, record *T) error {
+	return writeAuditLogs(tx, AuditOperationCreate, []auditChange[T]{ {after: record} })
+}
+
+// AuditAfterUpdate 更新后按前镜像主键重新查询后镜像，写入审计日志
+func AuditAfterUpdate[T any](tx *gorm.DB, record *T) error {
+	changes := make([]auditChange[T], 0)
+	for _, before := range loadAuditBeforeImages[T](tx, record) {
+		exprs := auditPrimaryKeyExprs(tx, before)
+		if len(exprs) == 0 {
+			continue
+		}
+		var after []*T
+		if err := auditTableSession(tx).Model(new(T)).Clauses(exprs...).Limit(1).Find(&after).Error; err != nil {
+			return err
+		}
+		change := auditChange[T]{before: before}
+		if len(after) > 0 {
+			change.after = after[0]
+		}
+		changes = append(changes, change)
+	}
+	return writeAuditLogs(tx, AuditOperationUpdate, changes)
+}
+
+// AuditAfterDelete 删除后写入审计日志
+func AuditAfterDelete[T any](tx *gorm.DB, record *T) error {
+	changes := make([]auditChange[T], 0)
+	for _, before := range loadAuditBeforeImages[T](tx, record) {
+		changes = append(changes, auditChange[T]{before: before})
+	}
+	return writeAuditLogs(tx, AuditOperationDelete, changes)
+}
+
+// auditBeforeImages 返回当前语句的前镜像集合，批量操作时按记录指针区分
+func auditBeforeImages(tx *gorm.DB) *sync.Map {
+	images, _ := tx.Statement.Settings.LoadOrStore(auditBeforeImageKey, &sync.Map{})
+	return images.(*sync.Map)
+}
+
+func loadAuditBeforeImages[T any](tx *gorm.DB, record *T) []*T {
+	before, ok := auditBeforeImages(tx).LoadAndDelete(record)
+	if !ok {
+		return nil
+	}
+	return before.([]*T)
+}
+
+// auditSession 在当前事务连接上开启不触发钩子的新会话
+func auditSession(tx *gorm.DB) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+}
+
+// auditTableSession 查询前后镜像的会话，沿用当前语句实际访问的表（分表、多租户路由后的表与 TableName() 不同）
+func auditTableSession(tx *gorm.DB) *gorm.DB {
+	session := auditSession(tx).Table(tx.Statement.Table)
+	if tx.Statement.TableExpr != nil {
+		session.Statement.TableExpr = tx.Statement.TableExpr
+	}
+	return session
+}
+
+// auditTargetTable 审计日志记录的表名：优先使用 DAO 路由后的表，直接使用 gorm 时为语句访问的表
+func auditTargetTable(tx *gorm.DB) string {
+	if table, ok := tx.Get(AuditTableKey); ok {
+		if name, _ := table.(string); name != "" {
+			return name
+		}
+	}
+	return tx.Statement.Table
+}
+
+// auditPrimaryKeyExprs 根据记录的非零主键值生成查询条件
+func auditPrimaryKeyExprs(tx *gorm.DB, record any) []clause.Expression {
+	exprs := make([]clause.Expression, 0)
+	if tx.Statement.Schema == nil || record == nil {
+		return exprs
+	}
+	value := reflect.Indirect(reflect.ValueOf(record))
+	for _, field := range tx.Statement.Schema.PrimaryFields {
+		if fieldValue, zero := field.ValueOf(tx.Statement.Context, value); !zero {
+			exprs = append(exprs, clause.Eq{
+				Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+				Value:  fieldValue,
+			})
+		}
+	}
+	return exprs
+}
+
+// auditRecordKey 以 JSON 形式返回记录主键
+func auditRecordKey(tx *gorm.DB, record any) string {
+	if tx.Statement.Schema == nil || record == nil {
+		return ""
+	}
+	value := reflect.Indirect(reflect.ValueOf(record))
+	keys := make(map[string]any)
+	for _, field := range tx.Statement.Schema.PrimaryFields {
+		keys[field.DBName], _ = field.ValueOf(tx.Statement.Context, value)
+	}
+	return auditJSON(keys)
+}
+
+func auditJSON(value any) string {
+	if value == nil || reflect.ValueOf(value).IsNil() {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func writeAuditLogs[T any](tx *gorm.DB, operation string, changes []auditChange[T]) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	actor := AuditActorFromContext(tx.Statement.Context)
+	now := time.Now()
+	logs := make([]*{{.AuditStructName}}, 0, len(changes))
+	for _, change := range changes {
+		keyRecord := change.after
+		if keyRecord == nil {
+			keyRecord = change.before
+		}
+		logs = append(logs, &{{.AuditStructName}}{
+			TargetTable: auditTargetTable(tx),
+			Operation:   operation,
+			RecordKey:   auditRecordKey(tx, keyRecord),
+			Actor:       actor,
+			BeforeData:  auditJSON(change.before),
+			AfterData:   auditJSON(change.after),
+			CreatedAt:   now,
+		})
+	}
+	return auditSession(tx).Create(&logs).Error
+}
+`
+
+// AuditDDL 审计日志表建表语句，按数据库类型输出
+const AuditDDL = `-- Code generated by jasonlabz/gentol. DO NOT EDIT.
+-- audit log table for {{.DBType}}
+{{if eq .DBType "mysql" -}}
+CREATE TABLE IF NOT EXISTS {{.AuditTableName}} (
+    id          BIGINT       NOT NULL AUTO_INCREMENT,
+    table_name  VARCHAR(128) NOT NULL,
+    operation   VARCHAR(16)  NOT NULL,
+    record_key  VARCHAR(512),
+    actor       VARCHAR(128),
+    before_data LONGTEXT,
+    after_data  LONGTEXT,
+    created_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_{{.AuditIndexName}}_table_record (table_name, record_key),
+    KEY idx_{{.AuditIndexName}}_created_at (created_at)
+) COMMENT = 'audit log';
+{{- else if or (eq .DBType "postgres") (eq .DBType "greenplum") -}}
+CREATE TABLE IF NOT EXISTS {{.AuditTableName}} (
+    id          BIGSERIAL    PRIMARY KEY,
+    table_name  VARCHAR(128) NOT NULL,
+    operation   VARCHAR(16)  NOT NULL,
+    record_key  VARCHAR(512),
+    actor       VARCHAR(128),
+    before_data TEXT,
+    after_data  TEXT,
+    created_at  TIMESTAMPTZ  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_{{.AuditIndexName}}_table_record ON {{.AuditTableName}} (table_name, record_key);
+CREATE INDEX IF NOT EXISTS idx_{{.AuditIndexName}}_created_at ON {{.AuditTableName}} (created_at);
+COMMENT ON TABLE {{.AuditTableName}} IS 'audit log';
+{{- else if eq .DBType "sqlite" -}}
+CREATE TABLE IF NOT EXISTS {{.AuditTableName}} (
+    id          INTEGER      PRIMARY KEY AUTOINCREMENT,
+    table_name  VARCHAR(128) NOT NULL,
+    operation   VARCHAR(16)  NOT NULL,
+    record_key  VARCHAR(512),
+    actor       VARCHAR(128),
+    before_data TEXT,
+    after_data  TEXT,
+    created_at  DATETIME     NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_{{.AuditIndexName}}_table_record ON {{.AuditTableName}} (table_name, record_key);
+CREATE INDEX IF NOT EXISTS idx_{{.AuditIndexName}}_created_at ON {{.AuditTableName}} (created_at);
+{{- else if eq .DBType "sqlserver" -}}
+CREATE TABLE {{.AuditTableName}} (
+    id          BIGINT        IDENTITY(1,1) PRIMARY KEY,
+    table_name  NVARCHAR(128) NOT NULL,
+    operation   NVARCHAR(16)  NOT NULL,
+    record_key  NVARCHAR(512),
+    actor       NVARCHAR(128),
+    before_data NVARCHAR(MAX),
+    after_data  NVARCHAR(MAX),
+    created_at  DATETIME2     NOT NULL
+);
+CREATE INDEX idx_{{.AuditIndexName}}_table_record ON {{.AuditTableName}} (table_name, record_key);
+CREATE INDEX idx_{{.AuditIndexName}}_created_at ON {{.AuditTableName}} (created_at);
+{{- else if eq .DBType "oracle" -}}
+CREATE TABLE {{.AuditTableName}} (
+    id          NUMBER(19)    GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    table_name  VARCHAR2(128) NOT NULL,
+    operation   VARCHAR2(16)  NOT NULL,
+    record_key  VARCHAR2(512),
+    actor       VARCHAR2(128),
+    before_data CLOB,
+    after_data  CLOB,
+    created_at  TIMESTAMP     NOT NULL
+);
+CREATE INDEX idx_{{.AuditIndexName}}_table_record ON {{.AuditTableName}} (table_name, record_key);
+CREATE INDEX idx_{{.AuditIndexName}}_created_at ON {{.AuditTableName}} (created_at);
+COMMENT ON TABLE {{.AuditTableName}} IS 'audit log';
+{{- else if eq .DBType "dm" -}}
+CREATE TABLE {{.AuditTableName}} (
+    id          BIGINT       IDENTITY(1,1) PRIMARY KEY,
+    table_name  VARCHAR(128) NOT NULL,
+    operation   VARCHAR(16)  NOT NULL,
+    record_key  VARCHAR(512),
+    actor       VARCHAR(128),
+    before_data CLOB,
+    after_data  CLOB,
+    created_at  TIMESTAMP    NOT NULL
+);
+CREATE INDEX idx_{{.AuditIndexName}}_table_record ON {{.AuditTableName}} (table_name, record_key);
+CREATE INDEX idx_{{.AuditIndexName}}_created_at ON {{.AuditTableName}} (created_at);
+COMMENT ON TABLE {{.AuditTableName}} IS 'audit log';
+{{- end}}
+`
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -30,6 +31,17 @@ func StoreTpl(key, content string) {
 	tplMap.Store(key, tpl)
 }
 
+// TplNames 返回全部内置模板名称（已排序）
+func TplNames() []string {
+	keys := make([]string, 0)
+	tplMap.Range(func(key, _ any) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
 const NotEditMark = `
 // Code generated by jasonlabz/gentol. DO NOT EDIT.
 // Code generated by jasonlabz/gentol. DO NOT EDIT.
@@ -53,6 +65,13 @@ type MetaType struct {
 	IsArray            bool // 标记是否为 PostgreSQL 数组类型，生成时使用 jsonb 替代原生数组
 }
 
+// TagOption 额外的结构体标签
+type TagOption struct {
+	Name      string `json:"name" yaml:"name"`           // 标签名，如 xml、yaml、form
+	Format    string `json:"format" yaml:"format"`       // 字段名格式：snake | upper_camel | lower_camel，默认 snake
+	OmitEmpty bool   `json:"omitempty" yaml:"omitempty"` // 是否追加 ,omitempty
+}
+
 type BaseConfig struct {
 	DBType                string
 	SchemaName            string
@@ -67,6 +86,14 @@ type BaseConfig struct {
 	ProtobufFormat        string
 	RunGoFmt              bool
 	UseSQLNullable        bool
+	ReadWriteSplit        bool
+	UseOtel               bool
+	TenantMode            string // 多租户路由方式：schema | suffix，为空时不启用
+	Sharding              bool   // 库中是否配置了分表
+	FileName              string // 生成文件名（不含后缀），默认与表名一致
+	TableComment          string // 表注释
+	ExtraTags             []*TagOption
+	ValidateTag           bool
 	AddGormAnnotation     bool
 	AddProtobufAnnotation bool
 }
@@ -248,6 +275,8 @@ func init() {
 	StoreTpl("model", Model)
 	StoreTpl("model_base", ModelBase)
 	StoreTpl("model_hook", ModelHook)
+	StoreTpl("model_audit", ModelAudit)
+	StoreTpl("audit_ddl", AuditDDL)
 	StoreTpl("dao", Dao)
 	StoreTpl("daoExt", DaoExt)
 	StoreTpl("dao_impl", DaoImpl)
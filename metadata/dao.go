@@ -15,6 +15,8 @@ type DaoMeta struct {
 	DaoPackageName   string
 	PrimaryKeyList   []*PrimaryKeyInfo
 	ColumnList       []*ColumnInfo
+	ShardFormat      string // 分表物理表名格式，非空时表示该表为分表逻辑表
+	Audited          bool   // 是否生成审计钩子，路由后的表名需要传给钩子
 }
 
 type PrimaryKeyInfo struct {
@@ -22,6 +24,7 @@ type PrimaryKeyInfo struct {
 	GoColumnType       string
 	GoColumnOriginType string
 	GoFieldName        string
+	Comment            string
 }
 
 func (m *DaoMeta) GenRenderData() map[string]any {
@@ -56,6 +59,7 @@ func (m *DaoMeta) GenRenderData() map[string]any {
 				GoColumnName:       UnderscoreToLowerCamelCase(columnInfo.ColumnName),
 				GoColumnType:       columnInfo.GoColumnType,
 				GoColumnOriginType: columnInfo.GoColumnOriginType,
+				Comment:            normalizeComment(columnInfo.Comment),
 			})
 		}
 	}
@@ -65,17 +69,32 @@ func (m *DaoMeta) GenRenderData() map[string]any {
 		"ModelPackageName":    m.ModelPackageName,
 		"DaoPackageName":      m.DaoPackageName,
 		"ModelStructName":     m.ModelStructName,
-		"ModelLowerCamelName": UnderscoreToLowerCamelCase(m.TableName),
+		"ModelLowerCamelName": m.lowerCamelName(),
 		"ModelShortName":      ToLower(strings.Split(m.ModelStructName, "")[0]),
 		"PrimaryKeyList":      m.PrimaryKeyList,
 		"ColumnList":          m.ColumnList,
 		"SchemaName":          m.SchemaName,
 		"TableName":           m.TableName,
 		"TitleTableName":      m.ModelStructName,
+		"TableComment":        splitCommentLines(m.TableComment),
+		"ReadWriteSplit":      m.ReadWriteSplit,
+		"UseOtel":             m.UseOtel,
+		"TenantMode":          m.TenantMode,
+		"Sharding":            m.Sharding,
+		"ShardFormat":         m.ShardFormat,
+		"Audited":             m.Audited,
 	}
 	return result
 }
 
+// lowerCamelName 实现类型名前缀；结构体名按命名策略改写过时跟随结构体名
+func (m *DaoMeta) lowerCamelName() string {
+	if m.ModelStructName == UnderscoreToUpperCamelCase(m.TableName) {
+		return UnderscoreToLowerCamelCase(m.TableName)
+	}
+	return UpperCamelCaseToLowerCamelCase(m.ModelStructName)
+}
+
 const Dao = NotEditMark + `
 package {{.DaoPackageName}}
 
@@ -83,11 +102,26 @@ import (
 	"context"
 
 	"{{.ModelModulePath}}"
+
+	// gentol:begin imports
+	// gentol:end
 )
 
+{{- range $i, $line := .TableComment}}
+// {{if eq $i 0}}{{$.ModelStructName}}Dao {{end}}{{$line}}
+{{- end}}
+{{- if .TableComment}}
+//
+{{- end}}
+// {{.ModelStructName}}Dao {{.TableName}} 表数据访问接口
 type {{.ModelStructName}}Dao interface {
 	// 可编辑自定义dao层逻辑
 	{{.ModelStructName}}DaoExt
+	{{- if .ShardFormat}}
+
+	// TableFor 根据分表键返回物理表名，DAO 方法按 ctx 中的分表键（{{.DaoPackageName}}.WithShardKey）自动路由
+	TableFor(key string) string
+	{{- end}}
 
 	// SelectByRawSQL 自定义SQL查询，满足连表查询场景
 	SelectByRawSQL(ctx context.Context, rawSQL string, result any) (err error)
@@ -96,6 +130,9 @@ type {{.ModelStructName}}Dao interface {
 	SelectAll(ctx context.Context, selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (records []*{{.ModelPackageName}}.{{.ModelStructName}}, err error)
 	
 	// SelectOneByPrimaryKey 通过主键查询记录
+	{{- range .PrimaryKeyList}}{{if .Comment}}
+	//   - {{.GoColumnName}}: {{.Comment}}
+	{{- end}}{{end}}
 	SelectOneByPrimaryKey(ctx context.Context, {{range .PrimaryKeyList}}{{.GoColumnName}} {{.GoColumnOriginType}}, {{end}}selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (record *{{.ModelPackageName}}.{{.ModelStructName}}, err error)
 	
 	// SelectRecordByCondition 通过指定条件查询记录
@@ -112,6 +149,9 @@ type {{.ModelStructName}}Dao interface {
 	DeleteByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition) (affect int64, err error)
 	
 	// DeleteByPrimaryKey 通过主键删除记录，返回删除记录数量
+	{{- range .PrimaryKeyList}}{{if .Comment}}
+	//   - {{.GoColumnName}}: {{.Comment}}
+	{{- end}}{{end}}
 	DeleteByPrimaryKey(ctx context.Context{{range .PrimaryKeyList}}, {{.GoColumnName}} {{.GoColumnOriginType}}{{end}}) (affect int64, err error)
 
 	// UpsertRecord 更新记录
@@ -124,6 +164,9 @@ type {{.ModelStructName}}Dao interface {
 	UpdateByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition, updateField {{.ModelPackageName}}.UpdateField) (affect int64, err error)
 	
 	// UpdateByPrimaryKey 更新主键的记录
+	{{- range .PrimaryKeyList}}{{if .Comment}}
+	//   - {{.GoColumnName}}: {{.Comment}}
+	{{- end}}{{end}}
 	UpdateByPrimaryKey(ctx context.Context, {{range .PrimaryKeyList}}{{.GoColumnName}} {{.GoColumnOriginType}}, {{end}}updateField {{.ModelPackageName}}.UpdateField) (affect int64, err error)
 	
 	// Insert 插入记录
@@ -139,7 +182,13 @@ type {{.ModelStructName}}Dao interface {
 	// BatchInsertOrUpdateOnDuplicateKey 批量插入记录，假如唯一键冲突则更新
 	BatchInsertOrUpdateOnDuplicateKey(ctx context.Context, records []*{{.ModelPackageName}}.{{.ModelStructName}},
 	uniqueKeys ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (affect int64, err error)
+
+	// gentol:begin methods
+	// gentol:end
 }
+
+// gentol:begin custom
+// gentol:end
 `
 
 const DaoExt = `
@@ -154,6 +203,9 @@ package impl
 
 import (
 	"context"
+	{{- if .ShardFormat}}
+	"fmt"
+	{{- end}}
 	"strings"
 
 	"gorm.io/gorm"
@@ -161,6 +213,9 @@ import (
 
 	"{{.DaoModulePath}}"
 	"{{.ModelModulePath}}"
+
+	// gentol:begin imports
+	// gentol:end
 )
 
 var {{.ModelLowerCamelName}}Dao {{.DaoPackageName}}.{{.ModelStructName}}Dao = &{{.ModelLowerCamelName}}DaoImpl{}
@@ -172,21 +227,78 @@ func Get{{.ModelStructName}}Dao() {{.DaoPackageName}}.{{.ModelStructName}}Dao {
 type {{.ModelLowerCamelName}}DaoImpl struct{}
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) tx(ctx context.Context) *gorm.DB {
-	tx, ok := ctx.Value("transactionDB").(*gorm.DB)
-	if ok {
-		return tx
+	if tx, ok := {{.DaoPackageName}}.TxFromContext(ctx); ok {
+		return {{if .ShardFormat}}{{.ModelShortName}}.requireShardKey(ctx, {{end}}{{if or .TenantMode .ShardFormat}}{{.ModelShortName}}.routed(ctx, tx){{else}}tx{{end}}{{if .ShardFormat}}){{end}}
+	}
+	return {{if .ShardFormat}}{{.ModelShortName}}.requireShardKey(ctx, {{end}}{{if or .TenantMode .ShardFormat}}{{.ModelShortName}}.routed(ctx, {{.DaoPackageName}}.DB()){{else}}{{.DaoPackageName}}.DB(){{end}}{{if .ShardFormat}}){{end}}
+}
+{{if .ReadWriteSplit}}
+// readTx 查询使用的连接：事务内沿用事务连接，否则交由读写路由选择从库（或被强制的主库）
+func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) readTx(ctx context.Context) *gorm.DB {
+	if tx, ok := {{.DaoPackageName}}.TxFromContext(ctx); ok {
+		return {{if .ShardFormat}}{{.ModelShortName}}.requireShardKey(ctx, {{end}}{{if or .TenantMode .ShardFormat}}{{.ModelShortName}}.routed(ctx, tx){{else}}tx{{end}}{{if .ShardFormat}}){{end}}
+	}
+	return {{if .ShardFormat}}{{.ModelShortName}}.requireShardKey(ctx, {{end}}{{if or .TenantMode .ShardFormat}}{{.ModelShortName}}.routed(ctx, {{.DaoPackageName}}.ReadDB(ctx)){{else}}{{.DaoPackageName}}.ReadDB(ctx){{end}}{{if .ShardFormat}}){{end}}
+}
+{{end}}
+{{- if .ShardFormat}}
+// TableFor 根据分表键返回物理表名
+func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) TableFor(key string) string {
+	return fmt.Sprintf({{printf "%q" .ShardFormat}}, key)
+}
+
+// requireShardKey 上下文未携带分表键时，逻辑表 {{.TableName}} 通常并不存在，返回 ErrShardKeyRequired 而不是访问逻辑表
+func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) requireShardKey(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if {{.DaoPackageName}}.ShardKeyFromContext(ctx) == "" {
+		_ = db.AddError(fmt.Errorf("%w: {{.TableName}}", {{.DaoPackageName}}.ErrShardKeyRequired))
 	}
-	return {{.DaoPackageName}}.DB()
+	return db
+}
+{{end}}
+{{- if or .TenantMode .ShardFormat}}
+// routed 切换到当前上下文实际访问的表{{if .Audited}}，并记录该表供审计日志使用{{end}}
+func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) routed(ctx context.Context, db *gorm.DB) *gorm.DB {
+	{{- if .Audited}}
+	table := {{.ModelShortName}}.table(ctx)
+	return db.Table(table).Set({{.ModelPackageName}}.AuditTableKey, table)
+	{{- else}}
+	return db.Table({{.ModelShortName}}.table(ctx))
+	{{- end}}
 }
 
+// table 返回当前上下文实际访问的表
+{{- if .ShardFormat}}，按分表键路由到对应分表{{end}}
+func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) table(ctx context.Context) string {
+	tableName := {{printf "%q" .TableName}}
+	{{- if .ShardFormat}}
+	if key := {{.DaoPackageName}}.ShardKeyFromContext(ctx); key != "" {
+		tableName = {{.ModelShortName}}.TableFor(key)
+	}
+	{{- end}}
+	{{- if .TenantMode}}
+	return {{.DaoPackageName}}.ResolveTable(ctx, {{printf "%q" .SchemaName}}, tableName)
+	{{- else if .SchemaName}}
+	return {{printf "%q" .SchemaName}} + "." + tableName
+	{{- else}}
+	return tableName
+	{{- end}}
+}
+{{end}}
+
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectByRawSQL(ctx context.Context, rawSQL string, result any) (err error) {
-	err = {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.SelectByRawSQL")
+	{{- end}}
+	err = {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Raw(rawSQL).Scan(result).Error
 	return
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectAll(ctx context.Context, selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (records []*{{.ModelPackageName}}.{{.ModelStructName}}, err error) {
-	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.SelectAll")
+	{{- end}}
+	tx := {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if len(selectFields) > 0 {
 		columns := make([]string, 0)
@@ -200,7 +312,10 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectAll(ctx context
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectOneByPrimaryKey(ctx context.Context, {{range .PrimaryKeyList}}{{.GoColumnName}} {{.GoColumnOriginType}}, {{end}}selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (record *{{.ModelPackageName}}.{{.ModelStructName}}, err error) {
-	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.SelectOneByPrimaryKey")
+	{{- end}}
+	tx := {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if len(selectFields) > 0 {
 		columns := make([]string, 0)
@@ -219,10 +334,13 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectOneByPrimaryKey
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectRecordByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition, selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (records []*{{.ModelPackageName}}.{{.ModelStructName}}, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.SelectRecordByCondition")
+	{{- end}}
 	if condition == nil {
 		return {{.ModelShortName}}.SelectAll(ctx, selectFields...)
 	}
-	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	tx := {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if len(selectFields) > 0 {
 		columns := make([]string, 0)
@@ -268,7 +386,10 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectRecordByConditi
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectPageRecordByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition, pageParam *{{.ModelPackageName}}.Pagination,
 	selectFields ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (records []*{{.ModelPackageName}}.{{.ModelStructName}}, err error) {
-	baseTx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.SelectPageRecordByCondition")
+	{{- end}}
+	baseTx := {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if len(selectFields) > 0 {
 		columns := make([]string, 0, len(selectFields))
@@ -313,7 +434,10 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) SelectPageRecordByCon
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) CountByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition) (count int64, err error) {
-	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.CountByCondition")
+	{{- end}}
+	tx := {{.ModelShortName}}.{{if .ReadWriteSplit}}readTx{{else}}tx{{end}}(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if condition != nil {
 		if len(condition.StringCondition) > 0 {
@@ -337,6 +461,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) CountByCondition(ctx
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) DeleteByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.DeleteByCondition")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx)
 	if condition != nil {
 		if len(condition.StringCondition) > 0 {
@@ -362,6 +489,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) DeleteByCondition(ctx
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) DeleteByPrimaryKey(ctx context.Context{{range .PrimaryKeyList}}, {{.GoColumnName}} {{.GoColumnOriginType}}{{end}}) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.DeleteByPrimaryKey")
+	{{- end}}
 	whereCondition := map[string]any{
  		{{ range .PrimaryKeyList -}}
 		"{{- .GoFieldName -}}": {{- .GoColumnName }},
@@ -374,6 +504,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) DeleteByPrimaryKey(ct
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpsertRecord(ctx context.Context, record *{{.ModelPackageName}}.{{.ModelStructName}}) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.UpsertRecord")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
 		Save(record)
 	affect = tx.RowsAffected
@@ -382,6 +515,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpsertRecord(ctx cont
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpsertRecords(ctx context.Context, records []*{{.ModelPackageName}}.{{.ModelStructName}}) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.UpsertRecords")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
 		Save(records)
 	affect = tx.RowsAffected
@@ -390,6 +526,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpsertRecords(ctx con
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpdateByCondition(ctx context.Context, condition *{{.ModelPackageName}}.Condition, updateField {{.ModelPackageName}}.UpdateField) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.UpdateByCondition")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{})
 	if condition != nil {
@@ -416,6 +555,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpdateByCondition(ctx
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpdateByPrimaryKey(ctx context.Context, {{range .PrimaryKeyList}}{{.GoColumnName}} {{.GoColumnOriginType}}, {{end}}updateField {{.ModelPackageName}}.UpdateField) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.UpdateByPrimaryKey")
+	{{- end}}
 	whereCondition := map[string]any{
  		{{ range .PrimaryKeyList -}}
 		"{{- .GoFieldName -}}": {{- .GoColumnName }},
@@ -431,6 +573,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) UpdateByPrimaryKey(ct
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) Insert(ctx context.Context, record *{{.ModelPackageName}}.{{.ModelStructName}}) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.Insert")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{}).
 		Create(&record)
@@ -440,6 +585,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) Insert(ctx context.Co
 }
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) BatchInsert(ctx context.Context, records []*{{.ModelPackageName}}.{{.ModelStructName}}) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.BatchInsert")
+	{{- end}}
 	tx := {{.ModelShortName}}.tx(ctx).WithContext(ctx).
 		Model(&{{.ModelPackageName}}.{{.ModelStructName}}{}).
 		Create(&records)
@@ -450,6 +598,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) BatchInsert(ctx conte
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) InsertOrUpdateOnDuplicateKey(ctx context.Context, record *{{.ModelPackageName}}.{{.ModelStructName}},
 	uniqueKeys ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.InsertOrUpdateOnDuplicateKey")
+	{{- end}}
 	columns := make([]clause.Column, 0)
 	for _, field := range uniqueKeys {
 		columns = append(columns, clause.Column{
@@ -469,6 +620,9 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) InsertOrUpdateOnDupli
 
 func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) BatchInsertOrUpdateOnDuplicateKey(ctx context.Context, records []*{{.ModelPackageName}}.{{.ModelStructName}},
 	uniqueKeys ...{{.ModelPackageName}}.{{.ModelStructName}}Field) (affect int64, err error) {
+	{{- if .UseOtel}}
+	ctx = {{.DaoPackageName}}.WithDaoMethod(ctx, "{{.ModelStructName}}Dao.BatchInsertOrUpdateOnDuplicateKey")
+	{{- end}}
 	columns := make([]clause.Column, 0)
 	for _, field := range uniqueKeys {
 		columns = append(columns, clause.Column{
@@ -486,8 +640,8 @@ func ({{.ModelShortName}} {{.ModelLowerCamelName}}DaoImpl) BatchInsertOrUpdateOn
 	return
 }
 
-
-
+// gentol:begin custom
+// gentol:end
 `
 
 const DaoExtImpl = `
@@ -504,16 +658,47 @@ package dao
 
 import (
 	"context"
+{{- if or .UseOtel .Sharding}}
+	"errors"
+{{- end}}
+{{- if .ReadWriteSplit}}
+	"sync/atomic"
+{{- end}}
+{{- if .UseOtel}}
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+{{- end}}
 
 	"gorm.io/gorm"
 )
 
 var gormDB *gorm.DB
 
+// transactionCtxKey 事务连接的上下文键，未导出以避免与其他包冲突
+type transactionCtxKey struct{}
+{{if .ReadWriteSplit}}
+var (
+	replicaDBs   []*gorm.DB
+	replicaIndex uint64
+)
+
+// primaryCtxKey 强制主库读的上下文键
+type primaryCtxKey struct{}
+{{end}}
 func SetGormDB(db *gorm.DB) {
 	if db == nil {
 		panic("db connection is nil")
 	}
+{{- if .UseOtel}}
+	if err := registerTelemetry(db); err != nil {
+		panic(err)
+	}
+{{- end}}
 	gormDB = db
 	return
 }
@@ -524,16 +709,231 @@ func DB() *gorm.DB {
 	}
 	return gormDB
 }
+{{if .ReadWriteSplit}}
+// SetReplicaDBs 注册从库连接，生成的 Select*/Count* 方法默认轮询路由到从库
+func SetReplicaDBs(dbs ...*gorm.DB) {
+	replicas := make([]*gorm.DB, 0, len(dbs))
+	for _, db := range dbs {
+		if db == nil {
+			panic("replica db connection is nil")
+		}
+{{- if .UseOtel}}
+		if err := registerTelemetry(db); err != nil {
+			panic(err)
+		}
+{{- end}}
+		replicas = append(replicas, db)
+	}
+	replicaDBs = replicas
+}
+
+// WithPrimary 标记后续查询强制走主库，用于写后立即读的一致性场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey{}, true)
+}
+
+// ReadDB 返回读连接：未注册从库或上下文要求强制主库时返回主库，否则轮询从库
+func ReadDB(ctx context.Context) *gorm.DB {
+	if forcePrimary, _ := ctx.Value(primaryCtxKey{}).(bool); forcePrimary || len(replicaDBs) == 0 {
+		return DB()
+	}
+	index := atomic.AddUint64(&replicaIndex, 1)
+	return replicaDBs[index%uint64(len(replicaDBs))]
+}
+{{end}}
+{{- if .Sharding}}
+// shardKeyCtxKey 分表键的上下文键
+type shardKeyCtxKey struct{}
+
+// ErrShardKeyRequired 分表 DAO 的上下文未通过 WithShardKey 设置分表键
+var ErrShardKeyRequired = errors.New("shard key is required in context")
+
+// WithShardKey 在上下文中设置分表键，分表 DAO 据此路由到物理表（如 "202401" -> order_202401），未设置时返回 ErrShardKeyRequired
+func WithShardKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, shardKeyCtxKey{}, key)
+}
+
+// ShardKeyFromContext 返回上下文中的分表键
+func ShardKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(shardKeyCtxKey{}).(string)
+	return key
+}
+{{end}}
+{{- if .TenantMode}}
+// tenantCtxKey 租户标识的上下文键
+type tenantCtxKey struct{}
+
+// TableResolver 根据上下文解析实际访问的表，schemaName、tableName 为生成代码时的 schema 与表名，
+// 返回值形如 "schema.table" 或 "table"，由 GORM 负责按方言加引号
+type TableResolver func(ctx context.Context, schemaName, tableName string) string
+
+var tableResolver TableResolver = defaultTableResolver
+
+// WithTenant 在上下文中设置租户标识
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenant)
+}
+
+// TenantFromContext 返回上下文中的租户标识
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantCtxKey{}).(string)
+	return tenant
+}
+
+// SetTableResolver 替换默认的表名解析器
+func SetTableResolver(resolver TableResolver) {
+	if resolver == nil {
+		panic("table resolver is nil")
+	}
+	tableResolver = resolver
+}
+
+// ResolveTable 返回当前上下文实际访问的表名
+func ResolveTable(ctx context.Context, schemaName, tableName string) string {
+	return tableResolver(ctx, schemaName, tableName)
+}
+
+// TableScope 供自定义查询复用多租户表路由，如 db.Scopes(dao.TableScope(ctx, "public", "user"))
+func TableScope(ctx context.Context, schemaName, tableName string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Table(ResolveTable(ctx, schemaName, tableName))
+	}
+}
+
+// defaultTableResolver 默认解析器：
+{{- if eq .TenantMode "suffix"}}租户标识作为表名后缀（table_tenant）
+{{- else}}租户标识作为 schema（tenant.table）
+{{- end}}，上下文无租户时使用生成时的 schema
+func defaultTableResolver(ctx context.Context, schemaName, tableName string) string {
+	tenant := TenantFromContext(ctx)
+{{- if eq .TenantMode "suffix"}}
+	if tenant != "" {
+		tableName = tableName + "_" + tenant
+	}
+{{- else}}
+	if tenant != "" {
+		return tenant + "." + tableName
+	}
+{{- end}}
+	if schemaName == "" {
+		return tableName
+	}
+	return schemaName + "." + tableName
+}
+{{end}}
+{{- if .UseOtel}}
+const (
+	instrumentationName = "{{.DaoModulePath}}"
+	telemetryStartKey   = "gentol:otel_start"
+)
+
+var (
+	tracer        = otel.Tracer(instrumentationName)
+	latencyMetric metric.Float64Histogram
+)
+
+// daoMethodCtxKey 当前 DAO 方法名的上下文键
+type daoMethodCtxKey struct{}
+
+// WithDaoMethod 在上下文中记录当前 DAO 方法名，作为链路与指标的维度
+func WithDaoMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, daoMethodCtxKey{}, method)
+}
 
+// registerTelemetry 注册 GORM 回调，为每条语句生成 span 并记录耗时直方图
+func registerTelemetry(db *gorm.DB) (err error) {
+	if latencyMetric == nil {
+		latencyMetric, err = otel.Meter(instrumentationName).Float64Histogram("db.client.operation.duration",
+			metric.WithUnit("s"), metric.WithDescription("duration of database operations issued by generated dao"))
+		if err != nil {
+			return
+		}
+	}
+	callback := db.Callback()
+	if callback.Query().Get("gentol:otel_before_query") != nil {
+		return
+	}
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("gentol:otel_before_create", beforeTelemetry("create")),
+		callback.Create().After("gorm:create").Register("gentol:otel_after_create", afterTelemetry("create")),
+		callback.Query().Before("gorm:query").Register("gentol:otel_before_query", beforeTelemetry("select")),
+		callback.Query().After("gorm:query").Register("gentol:otel_after_query", afterTelemetry("select")),
+		callback.Update().Before("gorm:update").Register("gentol:otel_before_update", beforeTelemetry("update")),
+		callback.Update().After("gorm:update").Register("gentol:otel_after_update", afterTelemetry("update")),
+		callback.Delete().Before("gorm:delete").Register("gentol:otel_before_delete", beforeTelemetry("delete")),
+		callback.Delete().After("gorm:delete").Register("gentol:otel_after_delete", afterTelemetry("delete")),
+		callback.Row().Before("gorm:row").Register("gentol:otel_before_row", beforeTelemetry("row")),
+		callback.Row().After("gorm:row").Register("gentol:otel_after_row", afterTelemetry("row")),
+		callback.Raw().Before("gorm:raw").Register("gentol:otel_before_raw", beforeTelemetry("raw")),
+		callback.Raw().After("gorm:raw").Register("gentol:otel_after_raw", afterTelemetry("raw")),
+	)
+}
+
+func beforeTelemetry(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		spanName := "gorm." + operation
+		if method, ok := db.Statement.Context.Value(daoMethodCtxKey{}).(string); ok {
+			spanName = method
+		}
+		db.Statement.Context, _ = tracer.Start(db.Statement.Context, spanName, trace.WithSpanKind(trace.SpanKindClient))
+		db.InstanceSet(telemetryStartKey, time.Now())
+	}
+}
+
+func afterTelemetry(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		method, _ := ctx.Value(daoMethodCtxKey{}).(string)
+		attrs := []attribute.KeyValue{
+			attribute.String("db.system", db.Dialector.Name()),
+			attribute.String("db.sql.table", db.Statement.Table),
+			attribute.String("db.operation", operation),
+			attribute.String("dao.method", method),
+		}
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attrs...)
+		span.SetAttributes(
+			attribute.String("db.statement", db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		}
+		span.End()
+		if start, ok := db.InstanceGet(telemetryStartKey); ok {
+			latencyMetric.Record(ctx, time.Since(start.(time.Time)).Seconds(),
+				metric.WithAttributes(append(attrs, attribute.Bool("error", db.Error != nil))...))
+		}
+	}
+}
+{{end}}
+// TxFromContext 返回上下文中绑定的事务连接
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(transactionCtxKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// RunTransaction 在事务中执行 f，f 返回错误时回滚，否则提交；
+// 上下文已处于事务中时直接加入该事务，由最外层事务统一提交或回滚
 func RunTransaction(ctx context.Context, f func(ctx context.Context) error) error {
-	// 使用 Transaction 方法并绑定上下文
+	if _, ok := TxFromContext(ctx); ok {
+		return f(ctx)
+	}
 	return DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		transCtx := context.WithValue(ctx, "transactionDB", tx)
-		// 在事务中创建用户
-		if err := f(transCtx); err != nil {
-			return err // 返回错误，事务会回滚
-		}
-		return nil // 返回 nil，事务会提交
+		return f(context.WithValue(ctx, transactionCtxKey{}, tx))
+	})
+}
+
+// RunTransactionWithSavepoint 与 RunTransaction 相同，但嵌套调用时在外层事务中创建保存点，
+// f 返回错误只回滚到该保存点，外层事务可继续执行
+func RunTransactionWithSavepoint(ctx context.Context, f func(ctx context.Context) error) error {
+	db := DB()
+	if tx, ok := TxFromContext(ctx); ok {
+		db = tx
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return f(context.WithValue(ctx, transactionCtxKey{}, tx))
 	})
 }
 `
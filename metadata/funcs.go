@@ -0,0 +1,337 @@
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// FuncMap 注册到所有模板（内置模板、template_dir 覆盖模板及自定义输出模板）的函数库
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// 命名转换
+		"camel":       UnderscoreToUpperCamelCase,
+		"lowerCamel":  UnderscoreToLowerCamelCase,
+		"snake":       CamelCaseToUnderscore,
+		"kebab":       func(s string) string { return strings.ReplaceAll(CamelCaseToUnderscore(s), "_", "-") },
+		"upper":       ToUpper,
+		"lower":       ToLower,
+		"upperFirst":  LowerCamelCaseToUpperCamelCase,
+		"lowerFirst":  UpperCamelCaseToLowerCamelCase,
+		"singularize": Singularize,
+		"pluralize":   Pluralize,
+
+		// 字段、索引
+		"hasColumn":    hasColumn,
+		"column":       findColumn,
+		"pkColumns":    pkColumns,
+		"indexColumns": indexColumns,
+
+		// Go 类型
+		"goType":     func(column *ColumnInfo) string { return column.GoColumnType },
+		"goBaseType": func(column *ColumnInfo) string { return column.GoColumnOriginType },
+		"zeroValue":  zeroValue,
+		"isNumber":   isNumberType,
+		"isString":   func(goType string) bool { return goType == "string" },
+		"isTime":     func(goType string) bool { return goType == "time.Time" },
+
+		// SQL、注释
+		"quote":   quoteIdent,
+		"comment": comment,
+		"wrap":    wrap,
+
+		// 字符串
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"squote":     func(s string) string { return "'" + s + "'" },
+		"dquote":     func(s string) string { return fmt.Sprintf("%q", s) },
+		"default":    defaultValue,
+
+		// 列表、字典
+		"list":      func(items ...any) []any { return items },
+		"first":     first,
+		"last":      last,
+		"append":    func(list []any, item any) []any { return append(list, item) },
+		"has":       has,
+		"uniq":      uniq,
+		"sortAlpha": sortAlpha,
+		"dict":      dict,
+		"add":       func(a, b int) int { return a + b },
+		"sub":       func(a, b int) int { return a - b },
+	}
+}
+
+// pluralRules 规则复数，与 Singularize 对应
+var pluralRules = []struct{ suffix, replace string }{
+	{"ss", "sses"}, {"sis", "ses"}, {"s", "ses"}, {"sh", "shes"}, {"ch", "ches"}, {"x", "xes"}, {"z", "zes"},
+}
+
+// Pluralize 将下划线名称的最后一个单词转为复数，如 order_item -> order_items
+func Pluralize(s string) string {
+	index := strings.LastIndex(s, "_")
+	prefix, word := s[:index+1], s[index+1:]
+	lower := ToLower(word)
+	if uncountableWords[lower] {
+		return s
+	}
+	for plural, singular := range irregularPlurals {
+		if singular == lower {
+			return prefix + plural
+		}
+	}
+	for _, rule := range pluralRules {
+		if strings.HasSuffix(lower, rule.suffix) {
+			return prefix + word[:len(word)-len(rule.suffix)] + rule.replace
+		}
+	}
+	if len(lower) > 1 && strings.HasSuffix(lower, "y") && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou") {
+		return prefix + word[:len(word)-1] + "ies"
+	}
+	return prefix + word + "s"
+}
+
+// findColumn 按列名查找字段，找不到时返回 nil
+func findColumn(columns []*ColumnInfo, name string) *ColumnInfo {
+	for _, column := range columns {
+		if strings.EqualFold(column.ColumnName, name) {
+			return column
+		}
+	}
+	return nil
+}
+
+// hasColumn 判断表是否包含指定列
+func hasColumn(columns []*ColumnInfo, name string) bool {
+	return findColumn(columns, name) != nil
+}
+
+// pkColumns 返回主键字段
+func pkColumns(columns []*ColumnInfo) []*ColumnInfo {
+	result := make([]*ColumnInfo, 0)
+	for _, column := range columns {
+		if column.IsPrimaryKey {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// indexColumns 返回指定索引的列；不指定索引名时返回所有非主键索引涉及的列（去重）
+func indexColumns(indexes []gorm.Index, name ...string) []string {
+	result := make([]string, 0)
+	for _, index := range indexes {
+		if len(name) > 0 {
+			if index.Name() == name[0] {
+				return index.Columns()
+			}
+			continue
+		}
+		if isPrimary, ok := index.PrimaryKey(); isPrimary && ok {
+			continue
+		}
+		for _, column := range index.Columns() {
+			if !contains(result, column) {
+				result = append(result, column)
+			}
+		}
+	}
+	return result
+}
+
+// zeroValue 返回 Go 类型的零值字面量
+func zeroValue(goType string) string {
+	switch {
+	case goType == "string":
+		return `""`
+	case goType == "bool":
+		return "false"
+	case isNumberType(goType):
+		return "0"
+	case strings.HasPrefix(goType, "*"), strings.HasPrefix(goType, "[]"),
+		strings.HasPrefix(goType, "map["), goType == "any", goType == "interface{}":
+		return "nil"
+	}
+	return goType + "{}"
+}
+
+// isNumberType 判断是否为数值类型
+func isNumberType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return true
+	}
+	return false
+}
+
+// quoteIdent 按数据库方言引用标识符，schema.table 形式逐段引用
+func quoteIdent(dbType, ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, part := range parts {
+		switch gormx.DBType(dbType) {
+		case gormx.DBTypeMySQL:
+			parts[i] = "`" + part + "`"
+		case gormx.DBTypeSqlserver:
+			parts[i] = "[" + part + "]"
+		default:
+			parts[i] = `"` + part + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// comment 将文本转为 Go 行注释，多行文本逐行加前缀
+func comment(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+strings.TrimSpace(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap 按宽度折行，用于长注释
+func wrap(width int, s string) string {
+	var (
+		lines []string
+		line  string
+	)
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indent 每行增加指定数量的空格缩进
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// join 用分隔符连接列表，元素按 fmt 格式化
+func join(sep string, list any) string {
+	items := toList(list)
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, fmt.Sprint(item))
+	}
+	return strings.Join(result, sep)
+}
+
+// defaultValue 值为空时使用默认值
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	if v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return def
+	}
+	return value
+}
+
+// toList 将任意切片、数组转为 []any
+func toList(list any) []any {
+	if list == nil {
+		return nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []any{list}
+	}
+	result := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		result = append(result, v.Index(i).Interface())
+	}
+	return result
+}
+
+func first(list any) any {
+	items := toList(list)
+	if len(items) == 0 {
+		return nil
+	}
+	return items[0]
+}
+
+func last(list any) any {
+	items := toList(list)
+	if len(items) == 0 {
+		return nil
+	}
+	return items[len(items)-1]
+}
+
+// has 判断列表是否包含元素
+func has(item, list any) bool {
+	for _, element := range toList(list) {
+		if reflect.DeepEqual(element, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// uniq 列表去重，保持原有顺序
+func uniq(list any) []any {
+	result := make([]any, 0)
+	for _, item := range toList(list) {
+		if !has(item, result) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// sortAlpha 按字符串排序
+func sortAlpha(list any) []string {
+	items := toList(list)
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, fmt.Sprint(item))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// dict 由键值对构造字典，用于向子模板传递多个参数
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments")
+	}
+	result := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
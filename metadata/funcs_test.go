@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"text/template"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		singular, want string
+	}{
+		{"user", "users"},
+		{"order_item", "order_items"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"movie", "movies"},
+		{"cookie", "cookies"},
+		{"status", "statuses"},
+		{"order_status", "order_statuses"},
+		{"bus", "buses"},
+		{"alias", "aliases"},
+		{"gas", "gases"},
+		{"atlas", "atlases"},
+		{"analysis", "analyses"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"branch", "branches"},
+		{"wish", "wishes"},
+		{"case", "cases"},
+		{"person", "people"},
+		{"child", "children"},
+		{"news", "news"},
+		{"user_data", "user_data"},
+	}
+	for _, tt := range tests {
+		got := Pluralize(tt.singular)
+		if got != tt.want {
+			t.Errorf("Pluralize(%q) = %q, want %q", tt.singular, got, tt.want)
+		}
+		// 复数再转回单数应得到原单词
+		if back := Singularize(got); back != tt.singular {
+			t.Errorf("Singularize(Pluralize(%q)) = %q, want %q", tt.singular, back, tt.singular)
+		}
+	}
+}
+
+// execFunc 以 FuncMap 渲染模板片段
+func execFunc(t *testing.T, text string, data any) string {
+	t.Helper()
+	tpl, err := template.New("test").Funcs(FuncMap()).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, data); err != nil {
+		t.Fatalf("execute %q: %v", text, err)
+	}
+	return sb.String()
+}
+
+func TestFuncMap(t *testing.T) {
+	indexes := []gorm.Index{
+		&migrator.Index{NameValue: "PRIMARY", ColumnList: []string{"id"}, PrimaryKeyValue: sql.NullBool{Bool: true, Valid: true}},
+		&migrator.Index{NameValue: "idx_user_status", ColumnList: []string{"user_id", "status"}},
+		&migrator.Index{NameValue: "uk_no", ColumnList: []string{"no", "user_id"}, UniqueValue: sql.NullBool{Bool: true, Valid: true}},
+	}
+	data := map[string]any{
+		"Indexes": indexes,
+		"Empty":   []string{},
+		"Text":    "first line\n  second line \n",
+	}
+	tests := []struct {
+		text, want string
+	}{
+		{`{{quote "mysql" "public.user"}}`, "`public`.`user`"},
+		{`{{quote "postgres" "public.user"}}`, `"public"."user"`},
+		{`{{quote "sqlserver" "dbo.user"}}`, "[dbo].[user]"},
+		{`{{quote "sqlite" "user"}}`, `"user"`},
+		{`{{comment .Text}}`, "// first line\n// second line"},
+		{`{{comment ""}}`, "//"},
+		{`{{wrap 10 "the quick brown fox jumps"}}`, "the quick\nbrown fox\njumps"},
+		{`{{wrap 3 "abcdef gh"}}`, "abcdef\ngh"},
+		{`{{wrap 10 "  "}}`, ""},
+		{`{{default "none" ""}}`, "none"},
+		{`{{default "none" "set"}}`, "set"},
+		{`{{default 1 0}}`, "1"},
+		{`{{default "none" .Empty}}`, "none"},
+		{`{{default "none" .Missing}}`, "none"},
+		{`{{with dict "name" "id" "size" 3}}{{.name}}:{{.size}}{{end}}`, "id:3"},
+		{`{{len (dict)}}`, "0"},
+		{`{{indexColumns .Indexes | join ","}}`, "user_id,status,no"},
+		{`{{indexColumns .Indexes "uk_no" | join ","}}`, "no,user_id"},
+		{`{{indexColumns .Indexes "PRIMARY" | join ","}}`, "id"},
+		{`{{indexColumns .Indexes "missing" | len}}`, "0"},
+		{`{{pluralize "status"}} {{singularize "statuses"}}`, "statuses status"},
+	}
+	for _, tt := range tests {
+		if got := execFunc(t, tt.text, data); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFuncMapDictErrors(t *testing.T) {
+	for _, text := range []string{`{{dict "a"}}`, `{{dict 1 2}}`} {
+		tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(text))
+		if err := tpl.Execute(&strings.Builder{}, nil); err == nil {
+			t.Errorf("%s should fail", text)
+		}
+	}
+}
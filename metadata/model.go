@@ -16,6 +16,8 @@ type ModelMeta struct {
 	ImportPkgList    []string
 	ColumnList       []*ColumnInfo
 	Indexs           []gorm.Index
+	Audited          bool   // 是否生成审计钩子
+	AuditTableName   string // 审计日志表名
 }
 
 type ColumnInfo struct {
@@ -47,8 +49,42 @@ type ColumnInfo struct {
 	AutoIncrement      bool
 	Nullable           bool
 	Comment            string
+	CommentLines       []string // 多行注释按行拆分，用作字段文档注释
 	DefaultValue       string
 	IsJSONB            bool // 标记是否为 PostgreSQL 数组类型（映射为 jsonb）
+	ReadOnly           bool // 数据库生成值的只读字段（生成列、计算列、rowversion），写入时忽略
+}
+
+// formatTagName 按格式生成标签中的字段名
+func formatTagName(format, columnName string) string {
+	switch format {
+	case "upper_camel":
+		return UnderscoreToUpperCamelCase(columnName)
+	case "lower_camel":
+		return UnderscoreToLowerCamelCase(columnName)
+	default:
+		name := CamelCaseToUnderscore(columnName)
+		// 清理连续的下划线
+		return cleanConsecutiveUnderscores(name)
+	}
+}
+
+// genValidateTag 根据字段约束生成 validate 标签：非空 -> required，字符串长度 -> max；
+// 可空字段为包装类型，validator 无法直接校验，只读字段不由调用方赋值，均不生成
+func genValidateTag(columnInfo *ColumnInfo) string {
+	if columnInfo.Nullable || columnInfo.ReadOnly {
+		return ""
+	}
+	rules := make([]string, 0, 2)
+	// 自增主键、有默认值的字段及 bool 字段（false 会被 required 判为空）不要求必填
+	if !columnInfo.AutoIncrement && columnInfo.DefaultValue == "" && columnInfo.GoColumnType != "bool" {
+		rules = append(rules, "required")
+	}
+	if columnInfo.GoColumnType == "string" && columnInfo.Length > 0 &&
+		strings.Contains(ToLower(columnInfo.DataBaseType), "char") {
+		rules = append(rules, fmt.Sprintf("max=%d", columnInfo.Length))
+	}
+	return strings.Join(rules, ",")
 }
 
 // IndexTagInfo 存储索引标签信息
@@ -77,6 +113,7 @@ func (m *ModelMeta) GenRenderData() map[string]any {
 		columnInfo.SQLNullableType = metaType.SQLNullableType
 
 		columnInfo.GoColumnName = UnderscoreToUpperCamelCase(columnInfo.ColumnName)
+		columnInfo.CommentLines = splitCommentLines(columnInfo.Comment)
 		columnInfo.Comment = normalizeComment(columnInfo.Comment)
 		columnInfo.TitleTableName = m.ModelStructName
 		columnInfo.GoUpperColumnName = ToUpper(columnInfo.ColumnName)
@@ -128,6 +165,9 @@ func (m *ModelMeta) GenRenderData() map[string]any {
 		gormTag := fmt.Sprintf("%s%s%s%s%s%s",
 			func() string {
 				var tag string
+				if columnInfo.ReadOnly {
+					tag = tag + "->;"
+				}
 				if columnInfo.IsPrimaryKey {
 					tag = tag + "primaryKey;"
 				}
@@ -173,24 +213,22 @@ func (m *ModelMeta) GenRenderData() map[string]any {
 			}(),
 		)
 
-		jsonTag := fmt.Sprintf("json:\"%s\"", func() string {
-			switch m.JsonFormat {
-			case "snake":
-				name := CamelCaseToUnderscore(columnInfo.ColumnName)
-				// 清理连续的下划线
-				return cleanConsecutiveUnderscores(name)
-			case "upper_camel":
-				return UnderscoreToUpperCamelCase(columnInfo.ColumnName)
-			case "lower_camel":
-				return UnderscoreToLowerCamelCase(columnInfo.ColumnName)
-			default:
-				name := CamelCaseToUnderscore(columnInfo.ColumnName)
-				// 清理连续的下划线
-				return cleanConsecutiveUnderscores(name)
-			}
-		}())
+		jsonTag := fmt.Sprintf("json:\"%s\"", formatTagName(m.JsonFormat, columnInfo.ColumnName))
 		gormTag = fmt.Sprintf("gorm:\"%s\"", strings.TrimSuffix(gormTag, ";"))
-		columnInfo.Tags = fmt.Sprintf("%s %s", gormTag, jsonTag)
+		tags := []string{gormTag, jsonTag}
+		for _, tagOption := range m.ExtraTags {
+			tagValue := formatTagName(tagOption.Format, columnInfo.ColumnName)
+			if tagOption.OmitEmpty {
+				tagValue += ",omitempty"
+			}
+			tags = append(tags, fmt.Sprintf("%s:\"%s\"", tagOption.Name, tagValue))
+		}
+		if m.ValidateTag {
+			if validateTag := genValidateTag(columnInfo); validateTag != "" {
+				tags = append(tags, fmt.Sprintf("validate:\"%s\"", validateTag))
+			}
+		}
+		columnInfo.Tags = strings.Join(tags, " ")
 	}
 	result := map[string]any{
 		"DBType":           m.DBType,
@@ -198,10 +236,17 @@ func (m *ModelMeta) GenRenderData() map[string]any {
 		"ModelStructName":  m.ModelStructName,
 		"ModelShortName":   ToLower(strings.Split(m.ModelStructName, "")[0]),
 		"ColumnList":       m.ColumnList,
+		"Indexes":          m.Indexs,
 		"SchemaName":       m.SchemaName,
 		"TableName":        m.TableName,
 		"TitleTableName":   m.ModelStructName,
+		"TableComment":     splitCommentLines(m.TableComment),
 		"ImportPkgList":    []string{},
+		"TenantMode":       m.TenantMode,
+		"Audited":          m.Audited,
+		"AuditTableName":   m.AuditTableName,
+		"AuditStructName":  UnderscoreToUpperCamelCase(m.AuditTableName),
+		"AuditIndexName":   strings.ReplaceAll(m.AuditTableName, ".", "_"),
 		"SchemaQuota": func() bool {
 			if m.DBType == string(gormx.DBTypePostgres) ||
 				m.DBType == string(gormx.DBTypeGreenplum) ||
@@ -315,6 +360,17 @@ func normalizeComment(comment string) string {
 	return strings.Join(strings.Fields(comment), " ")
 }
 
+// splitCommentLines 将注释按行拆分，去掉空行及多余空白
+func splitCommentLines(comment string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(comment, "\r\n", "\n"), "\n") {
+		if line = normalizeComment(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 // Model used as a variable because it cannot load template file after packed, params still can pass file
 const Model = NotEditMark + `
 package {{.ModelPackageName}}
@@ -326,6 +382,9 @@ import (
 	"github.com/jasonlabz/null"
 	"github.com/satori/go.uuid"
 	{{range .ImportPkgList}}{{.}} ` + "\n" + `{{end}}
+
+	// gentol:begin imports
+	// gentol:end
 )
 
 var (
@@ -337,18 +396,29 @@ var (
 
 type {{.TitleTableName}}Field string
 
+{{- range $i, $line := .TableComment}}
+// {{if eq $i 0}}{{$.ModelStructName}} {{end}}{{$line}}
+{{- end}}
+{{- if .TableComment}}
+//
+{{- end}}
 // {{.ModelStructName}} struct is mapping to the {{.TableName}} table
 type {{.ModelStructName}} struct {
     {{range .ColumnList}}
  
+    {{if gt (len .CommentLines) 1}}{{range .CommentLines}}// {{.}}
+    {{end}}{{end -}}
     {{if eq .GoColumnName "TableName" }}{{.GoColumnName}}_{{ else }}{{.GoColumnName}}{{ end }} {{.GoColumnType}} ` + "`{{.Tags}}` " +
-	"// Comment: {{if .Comment}}{{.Comment}}{{else}}no comment{{end}} " +
+	"{{if le (len .CommentLines) 1}}// Comment: {{if .Comment}}{{.Comment}}{{else}}no comment{{end}} {{end}}" +
 	`{{end}}
 }
 
 func ({{.ModelShortName}} *{{.ModelStructName}}) TableName() string {
 {{- if .TableName -}}
-	{{- if eq .DBType "postgres" -}}
+	{{- if .TenantMode}}
+	// 多租户模式下不固定 schema，实际表名由 DAO 层按上下文解析
+	return "{{if .TableQuota -}}\"{{.TableName}}\"{{- else}}{{.TableName}}{{- end}}"
+	{{- else if eq .DBType "postgres" -}}
 		{{- if and .SchemaName (ne .SchemaName "public") -}}
 	return "{{if .SchemaQuota -}}\"{{.SchemaName}}\"{{- else}}{{.SchemaName}}{{- end}}.{{if .TableQuota -}}\"{{.TableName}}\"{{- else}}{{.TableName}}{{- end}}"
 		{{- else -}}
@@ -516,6 +586,8 @@ func ({{.ModelShortName}} *{{.ModelStructName}}Condition) Build() *Condition {
 	return &{{.ModelShortName}}.Condition
 }
 
+// gentol:begin custom
+// gentol:end
 `
 
 // ModelHook hook file (no overwrite if file is existed), provide func BeforeCreate、AfterUpdate、BeforeDelete etc.
@@ -546,32 +618,57 @@ func ({{.ModelShortName}} *{{.ModelStructName}}) BeforeCreate(tx *gorm.DB) (err
 
 // AfterCreate invoked after create, return an error.
 func ({{.ModelShortName}} *{{.ModelStructName}}) AfterCreate(tx *gorm.DB) (err error) {
+{{- if .Audited}}
+	// 写入审计日志（后镜像）
+	return AuditAfterCreate(tx, {{.ModelShortName}})
+{{- else}}
 	// TODO: something
 	return
+{{- end}}
 }
 
 // BeforeUpdate invoked before update, return an error.
 func ({{.ModelShortName}} *{{.ModelStructName}}) BeforeUpdate(tx *gorm.DB) (err error) {
+{{- if .Audited}}
+	// 记录受影响记录的前镜像
+	return AuditBeforeChange(tx, {{.ModelShortName}})
+{{- else}}
 	// TODO: something
 	return 
+{{- end}}
 }
 
 // AfterUpdate invoked after update, return an error.
 func ({{.ModelShortName}} *{{.ModelStructName}}) AfterUpdate(tx *gorm.DB) (err error) {
+{{- if .Audited}}
+	// 写入审计日志（前后镜像）
+	return AuditAfterUpdate(tx, {{.ModelShortName}})
+{{- else}}
 	// TODO: something
 	return
+{{- end}}
 }
 
 // BeforeDelete invoked before delete, return an error.
 func ({{.ModelShortName}} *{{.ModelStructName}}) BeforeDelete(tx *gorm.DB) (err error) {
+{{- if .Audited}}
+	// 记录受影响记录的前镜像
+	return AuditBeforeChange(tx, {{.ModelShortName}})
+{{- else}}
 	// TODO: something
 	return 
+{{- end}}
 }
 
 // AfterDelete invoked after delete, return an error.
 func ({{.ModelShortName}} *{{.ModelStructName}}) AfterDelete(tx *gorm.DB) (err error) {
+{{- if .Audited}}
+	// 写入审计日志（前镜像）
+	return AuditAfterDelete(tx, {{.ModelShortName}})
+{{- else}}
 	// TODO: something
 	return
+{{- end}}
 }
 
 // AfterFind invoked after find, return an error.
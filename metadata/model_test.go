@@ -0,0 +1,100 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenValidateTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ColumnInfo
+		want   string
+	}{
+		{"required varchar", ColumnInfo{GoColumnType: "string", DataBaseType: "varchar", Length: 64}, "required,max=64"},
+		{"required char upper type", ColumnInfo{GoColumnType: "string", DataBaseType: "CHAR", Length: 2}, "required,max=2"},
+		{"text has no max", ColumnInfo{GoColumnType: "string", DataBaseType: "text", Length: 65535}, "required"},
+		{"required number", ColumnInfo{GoColumnType: "int64", DataBaseType: "bigint"}, "required"},
+		{"auto increment", ColumnInfo{GoColumnType: "int64", DataBaseType: "bigint", AutoIncrement: true}, ""},
+		{"default value", ColumnInfo{GoColumnType: "string", DataBaseType: "varchar", Length: 8, DefaultValue: "'a'"}, "max=8"},
+		{"bool", ColumnInfo{GoColumnType: "bool", DataBaseType: "boolean"}, ""},
+		{"nullable", ColumnInfo{GoColumnType: "string", DataBaseType: "varchar", Length: 64, Nullable: true}, ""},
+		{"read only", ColumnInfo{GoColumnType: "string", DataBaseType: "varchar", Length: 64, ReadOnly: true}, ""},
+	}
+	for _, tt := range tests {
+		if got := genValidateTag(&tt.column); got != tt.want {
+			t.Errorf("%s: genValidateTag() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTagName(t *testing.T) {
+	tests := []struct {
+		format, column, want string
+	}{
+		{"", "user_name", "user_name"},
+		{"snake", "UserName", "user_name"},
+		{"snake", "user__name", "user_name"},
+		{"upper_camel", "user_name", "UserName"},
+		{"upper_camel", "user_id", "UserID"},
+		{"lower_camel", "user_name", "userName"},
+		{"lower_camel", "created_at", "createdAt"},
+		{"lower_camel", "NAME", "name"},
+	}
+	for _, tt := range tests {
+		if got := formatTagName(tt.format, tt.column); got != tt.want {
+			t.Errorf("formatTagName(%q, %q) = %q, want %q", tt.format, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestModelTags(t *testing.T) {
+	meta := &ModelMeta{
+		BaseConfig: BaseConfig{
+			DBType:     "mysql",
+			TableName:  "user",
+			JsonFormat: "lower_camel",
+			ExtraTags: []*TagOption{
+				{Name: "yaml"},
+				{Name: "form", Format: "lower_camel", OmitEmpty: true},
+			},
+			ValidateTag: true,
+		},
+		ModelPackageName: "model",
+		ModelStructName:  "User",
+		ColumnList: []*ColumnInfo{
+			{ColumnName: "id", ColumnType: "bigint", DataBaseType: "bigint", IsPrimaryKey: true, AutoIncrement: true},
+			{ColumnName: "user_name", ColumnType: "varchar(64)", DataBaseType: "varchar", Length: 64},
+			{ColumnName: "remark", ColumnType: "varchar(255)", DataBaseType: "varchar", Length: 255, Nullable: true},
+		},
+	}
+	meta.GenRenderData()
+	want := []string{
+		`gorm:"primaryKey;autoIncrement;column:id;not null;type:bigint" json:"id" yaml:"id" form:"id,omitempty"`,
+		`gorm:"column:user_name;not null;type:varchar(64)" json:"userName" yaml:"user_name" form:"userName,omitempty" validate:"required,max=64"`,
+		`gorm:"column:remark;type:varchar(255)" json:"remark" yaml:"remark" form:"remark,omitempty"`,
+	}
+	for i, column := range meta.ColumnList {
+		if column.Tags != want[i] {
+			t.Errorf("%s tags =\n%s\nwant\n%s", column.ColumnName, column.Tags, want[i])
+		}
+	}
+}
+
+func TestSplitCommentLines(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    []string
+	}{
+		{"", []string{}},
+		{"  用户  ID ", []string{"用户 ID"}},
+		{"状态\n0 禁用\n\n1\t启用\n", []string{"状态", "0 禁用", "1 启用"}},
+		{"a\r\nb", []string{"a", "b"}},
+		{"\n \n", []string{}},
+	}
+	for _, tt := range tests {
+		if got := splitCommentLines(tt.comment); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommentLines(%q) = %q, want %q", tt.comment, got, tt.want)
+		}
+	}
+}
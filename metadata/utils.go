@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -130,7 +131,7 @@ func DmTrans(columnType string) (metaType MetaType) {
 			metaType.GureguNullableType = "[]byte"
 			metaType.ValueFormat = "'%v'"
 		} else {
-			fmt.Printf("unknown DM column type: %s, default to string\n", columnType)
+			log.Printf("unknown DM column type: %s, default to string", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -223,7 +224,7 @@ func SQLiteTrans(columnType string) (metaType MetaType) {
 			metaType.GureguNullableType = "null.Time"
 			metaType.ValueFormat = "'%v'"
 		} else {
-			fmt.Printf("unknown SQLite column type: %s, default to string\n", columnType)
+			log.Printf("unknown SQLite column type: %s, default to string", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -313,7 +314,7 @@ func PostgresTrans(columnType string) (metaType MetaType) {
 			baseType := strings.TrimSuffix(columnType, "[]")
 			return transPostgresArray(baseType, columnType)
 		} else {
-			fmt.Printf("unknown column type : %s, replace it with \"string\"\n", columnType)
+			log.Printf("unknown column type : %s, replace it with \"string\"", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -434,7 +435,7 @@ func MySQLTrans(columnType string) (metaType MetaType) {
 			metaType.GureguNullableType = "null.String"
 			metaType.ValueFormat = "'%v'"
 		} else {
-			fmt.Printf("unknown column type : %s, replace it with \"string\"\n", columnType)
+			log.Printf("unknown column type : %s, replace it with \"string\"", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -521,7 +522,7 @@ func SQLServerTrans(columnType string) (metaType MetaType) {
 			metaType.GureguNullableType = "null.Float"
 			metaType.ValueFormat = "%v"
 		} else {
-			fmt.Printf("unknown column type : %s, replace it with \"string\"\n", columnType)
+			log.Printf("unknown column type : %s, replace it with \"string\"", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -614,7 +615,7 @@ func OracleTrans(columnType string) (metaType MetaType) {
 			metaType.GureguNullableType = "null.String"
 			metaType.ValueFormat = "'%v'"
 		} else {
-			fmt.Printf("unknown column type : %s, replace it with \"string\"\n", columnType)
+			log.Printf("unknown column type : %s, replace it with \"string\"", columnType)
 			metaType.GoType = "string"
 			metaType.SQLNullableType = "sql.NullString"
 			metaType.GureguNullableType = "null.String"
@@ -634,13 +635,34 @@ func ToLower(s string) string {
 	return strings.ToLower(s)
 }
 
+// abbreviationSpelling 自定义缩写的拼写（如 OAuth），未登记的缩写按全大写输出
+var abbreviationSpelling = map[string]string{}
+
+// RegisterAbbreviations 追加缩写词，如 SKU、OAuth，生成驼峰名称时按给定拼写输出
+func RegisterAbbreviations(words ...string) {
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		upper := ToUpper(word)
+		abbreviationMap[upper] = true
+		lowerAbbreviationMap[ToLower(word)] = true
+		if word != upper {
+			abbreviationSpelling[upper] = word
+		}
+	}
+}
+
 // UnderscoreToUpperCamelCase 下划线单词转为大写驼峰单词
 func UnderscoreToUpperCamelCase(s string) string {
 	s = handleOtherCase(s)
 	splitList := strings.Split(s, "_")
 	for index, item := range splitList {
 		_, ok := abbreviationMap[ToUpper(item)]
-		if ok {
+		if spelling, custom := abbreviationSpelling[ToUpper(item)]; custom {
+			splitList[index] = spelling
+		} else if ok {
 			splitList[index] = ToUpper(item)
 		} else {
 			splitList[index] = strings.Title(item)
@@ -650,6 +672,98 @@ func UnderscoreToUpperCamelCase(s string) string {
 	return s
 }
 
+// irregularPlurals 不规则复数
+var irregularPlurals = map[string]string{
+	"people":   "person",
+	"men":      "man",
+	"women":    "woman",
+	"children": "child",
+	"feet":     "foot",
+	"teeth":    "tooth",
+	"mice":     "mouse",
+	"geese":    "goose",
+	"indices":  "index",
+	"matrices": "matrix",
+	"criteria": "criterion",
+	"buses":    "bus",
+	"aliases":  "alias",
+	"viruses":  "virus",
+	"campuses": "campus",
+	"bonuses":  "bonus",
+	"censuses": "census",
+	"focuses":  "focus",
+	"pies":     "pie",
+	"ties":     "tie",
+	"gases":    "gas",
+	"atlases":  "atlas",
+	"canvases": "canvas",
+	"biases":   "bias",
+}
+
+// singularWords 以 -as 结尾的单数单词，不能按复数去掉 s；-us、-ss、-is 结尾的单词由 Singularize 统一保留
+var singularWords = map[string]bool{
+	"gas":    true,
+	"atlas":  true,
+	"canvas": true,
+	"bias":   true,
+	"alias":  true,
+}
+
+// singularSuffixes 以 -ses 结尾、单数不是去掉 s 的后缀，按顺序匹配
+var singularSuffixes = [][2]string{
+	{"atuses", "atus"},   // statuses
+	{"yses", "ysis"},     // analyses
+	{"theses", "thesis"}, // hypotheses
+	{"gnoses", "gnosis"}, // diagnoses
+	{"crises", "crisis"},
+}
+
+// uncountableWords 单复数同形或不可数的单词
+var uncountableWords = map[string]bool{
+	"data":        true,
+	"metadata":    true,
+	"info":        true,
+	"information": true,
+	"news":        true,
+	"series":      true,
+	"species":     true,
+	"equipment":   true,
+	"sms":         true,
+}
+
+// Singularize 将下划线表名的最后一个单词转为单数，如 order_items -> order_item
+func Singularize(s string) string {
+	index := strings.LastIndex(s, "_")
+	prefix, word := s[:index+1], s[index+1:]
+	lower := ToLower(word)
+	switch {
+	case uncountableWords[lower], singularWords[lower]:
+		return s
+	case irregularPlurals[lower] != "":
+		return prefix + irregularPlurals[lower]
+	}
+	for _, suffix := range singularSuffixes {
+		if strings.HasSuffix(lower, suffix[0]) {
+			return prefix + word[:len(word)-len(suffix[0])] + suffix[1]
+		}
+	}
+	switch {
+	// 元音 + v/k 之后的 ies 只去掉 s，如 movies -> movie、cookies -> cookie
+	case len(lower) > 4 && strings.HasSuffix(lower, "ies") && strings.ContainsRune("vk", rune(lower[len(lower)-4])) &&
+		strings.ContainsRune("aeiou", rune(lower[len(lower)-5])):
+		word = word[:len(word)-1]
+	case len(lower) > 3 && strings.HasSuffix(lower, "ies"):
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+	case len(lower) > 1 && strings.HasSuffix(lower, "s"):
+		word = word[:len(word)-1]
+	}
+	return prefix + word
+}
+
 // LowerCamelCaseToUpperCamelCase 小写驼峰单词转为大写驼峰单词
 func LowerCamelCaseToUpperCamelCase(s string) string {
 	if len(s) == 0 {
@@ -0,0 +1,64 @@
+package metadata
+
+import "testing"
+
+func TestSingularize(t *testing.T) {
+	tests := []struct {
+		plural, want string
+	}{
+		{"users", "user"},
+		{"order_items", "order_item"},
+		{"categories", "category"},
+		{"cities", "city"},
+		{"skies", "sky"},
+		{"movies", "movie"},
+		{"cookies", "cookie"},
+		{"rookies", "rookie"},
+		{"statuses", "status"},
+		{"order_statuses", "order_status"},
+		{"buses", "bus"},
+		{"aliases", "alias"},
+		{"analyses", "analysis"},
+		{"diagnoses", "diagnosis"},
+		{"hypotheses", "hypothesis"},
+		{"crises", "crisis"},
+		{"addresses", "address"},
+		{"cases", "case"},
+		{"houses", "house"},
+		{"responses", "response"},
+		{"databases", "database"},
+		{"boxes", "box"},
+		{"branches", "branch"},
+		{"wishes", "wish"},
+		{"people", "person"},
+		{"user_children", "user_child"},
+		{"pies", "pie"},
+		{"status", "status"},
+		{"class", "class"},
+		{"basis", "basis"},
+		{"news", "news"},
+		{"user_data", "user_data"},
+		{"gas", "gas"},
+		{"bus", "bus"},
+		{"campus", "campus"},
+		{"atlas", "atlas"},
+		{"alias", "alias"},
+		{"canvas", "canvas"},
+		{"user_bias", "user_bias"},
+		{"glass", "glass"},
+		{"address", "address"},
+		{"gases", "gas"},
+		{"atlases", "atlas"},
+		{"ideas", "idea"},
+		{"areas", "area"},
+		{"schemas", "schema"},
+		{"s", "s"},
+		{"USERS", "USER"},
+		{"Movies", "Movie"},
+	}
+	for _, tt := range tests {
+		if got := Singularize(tt.plural); got != tt.want {
+			t.Errorf("Singularize(%q) = %q, want %q", tt.plural, got, tt.want)
+		}
+	}
+}
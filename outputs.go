@@ -0,0 +1,201 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// renderData 直接使用已构建好的渲染数据
+type renderData map[string]any
+
+func (d renderData) GenRenderData() map[string]any {
+	return d
+}
+
+// outputTables 当前库各 schema 下表的渲染数据，供 schema、database 范围的自定义输出使用
+var outputTables map[string][]map[string]any
+
+// compileOutputs 校验自定义输出配置
+func compileOutputs() error {
+	for _, output := range configx.TableConfigs.Outputs {
+		if err := output.Compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasGroupOutputs 是否配置了 schema、database 范围的自定义输出
+func hasGroupOutputs() bool {
+	for _, output := range configx.TableConfigs.Outputs {
+		if output.GetScope() != configx.OutputScopeTable {
+			return true
+		}
+	}
+	return false
+}
+
+// tableRenderData 合并 model 与 dao 的渲染数据，同名字段以 model 为准
+func tableRenderData(dbInfo *configx.DBTableInfo, schema, tableName, tableComment string,
+	columnTypes []gorm.ColumnType, indexes []gorm.Index, readOnlyColumns []string) map[string]any {
+	modelData := newModelMeta(dbInfo, schema, tableName, tableComment, columnTypes, indexes, readOnlyColumns)
+	data := modelData.GenRenderData()
+	for key, value := range newDaoMeta(dbInfo, schema, tableName, tableComment, columnTypes).GenRenderData() {
+		if _, ok := data[key]; !ok {
+			data[key] = value
+		}
+	}
+	data["DBName"] = dbInfo.DBName
+	data["FileName"] = modelData.FileName
+	return data
+}
+
+// collectOutputTable 记录表的渲染数据，未变化而跳过生成的表同样需要记录
+func collectOutputTable(data map[string]any) {
+	schema, _ := data["SchemaName"].(string)
+	outputTables[schema] = append(outputTables[schema], data)
+}
+
+// WriteTableOutputs 生成 table 范围的自定义输出
+func WriteTableOutputs(data map[string]any) {
+	for _, output := range configx.TableConfigs.Outputs {
+		if output.GetScope() == configx.OutputScopeTable {
+			writeOutput(output, data)
+		}
+	}
+}
+
+// WriteGroupOutputs 生成 schema、database 范围的自定义输出，渲染数据中的 Tables 为对应范围内全部表的渲染数据
+func WriteGroupOutputs(dbInfo *configx.DBTableInfo) {
+	schemas := make([]string, 0, len(outputTables))
+	allTables := make([]map[string]any, 0)
+	for schema := range outputTables {
+		schemas = append(schemas, schema)
+	}
+	slices.Sort(schemas)
+	for _, schema := range schemas {
+		tables := outputTables[schema]
+		slices.SortFunc(tables, func(a, b map[string]any) int {
+			return compareString(a["TableName"], b["TableName"])
+		})
+		allTables = append(allTables, tables...)
+	}
+
+	for _, schema := range schemas {
+		renderedFiles = nil
+		for _, output := range configx.TableConfigs.Outputs {
+			if output.GetScope() == configx.OutputScopeSchema {
+				writeOutput(output, map[string]any{
+					"DBName":     dbInfo.DBName,
+					"DBType":     dbInfo.DBType,
+					"SchemaName": schema,
+					"Tables":     outputTables[schema],
+				})
+			}
+		}
+		recordGroupOutputs(manifestKey(dbInfo, schema, schemaOutputsName))
+	}
+
+	renderedFiles = nil
+	for _, output := range configx.TableConfigs.Outputs {
+		if output.GetScope() == configx.OutputScopeDatabase {
+			writeOutput(output, map[string]any{
+				"DBName": dbInfo.DBName,
+				"DBType": dbInfo.DBType,
+				"Tables": allTables,
+			})
+		}
+	}
+	recordGroupOutputs(manifestKey(dbInfo, "", databaseOutputsName))
+}
+
+// schema、database 范围自定义输出在生成清单中的名称，与表一样参与清理：不再生成的文件在 --prune 时删除
+const (
+	schemaOutputsName   = "@schema_outputs"
+	databaseOutputsName = "@database_outputs"
+)
+
+// recordGroupOutputs 将本次写出的范围输出文件记入生成清单；范围输出每次都重新渲染，不记录指纹
+func recordGroupOutputs(key string) {
+	if len(renderedFiles) == 0 {
+		return
+	}
+	seenTables[key] = true
+	manifest.record(key, "", renderedFiles)
+}
+
+// compareString 按字符串比较两个渲染数据字段
+func compareString(a, b any) int {
+	sa, _ := a.(string)
+	sb, _ := b.(string)
+	switch {
+	case sa < sb:
+		return -1
+	case sa > sb:
+		return 1
+	}
+	return 0
+}
+
+// writeOutput 按覆盖策略渲染一个自定义输出
+func writeOutput(output *configx.OutputInfo, data map[string]any) {
+	tpl, err := loadOutputTpl(output.Template)
+	if err != nil {
+		log.Printf("load output template %s failed: %v", output.Template, err)
+		genReport.Failed = append(genReport.Failed, output.Template)
+		return
+	}
+	outPath, err := output.OutputPath(data)
+	if err != nil {
+		log.Printf("render output path %s failed: %v", output.Path, err)
+		genReport.Failed = append(genReport.Failed, output.Path)
+		return
+	}
+	ff, _ := filepath.Abs(outPath)
+	writeWithPolicy(output.GetOverwrite(), ff, func() error {
+		return RenderingTemplate(tpl, renderData(data), ff, true)
+	})
+}
+
+// writeWithPolicy 按覆盖策略决定是否写出文件，write 负责实际渲染、写出
+func writeWithPolicy(policy, ff string, write func() error) {
+	switch policy {
+	case configx.OverwriteOnce:
+		if IsExist(ff) {
+			keepFile(ff)
+			return
+		}
+		if manifest.generatedOnce(ff) {
+			return
+		}
+	case configx.OverwriteNever:
+		if IsExist(ff) {
+			keepFile(ff)
+			return
+		}
+	}
+	if err := write(); err != nil {
+		log.Println("err occured: ", err)
+		return
+	}
+	if policy == configx.OverwriteOnce && !genOptions.DryRun {
+		manifest.markOnce(ff)
+	}
+}
+
+// loadOutputTpl 读取自定义输出的模板文件
+func loadOutputTpl(path string) (*metadata.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	absPath, _ := filepath.Abs(path)
+	return &metadata.Template{Name: "file://" + absPath, Content: string(content)}, nil
+}
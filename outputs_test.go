@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// setOutputs 替换自定义输出配置，并还原测试修改的生成清单、已处理表及范围输出数据
+func setOutputs(t *testing.T, outputs ...*configx.OutputInfo) {
+	t.Helper()
+	resetManifest(t)
+	savedTables, savedOutputs := outputTables, configx.TableConfigs.Outputs
+	t.Cleanup(func() {
+		outputTables, configx.TableConfigs.Outputs = savedTables, savedOutputs
+	})
+	outputTables = map[string][]map[string]any{}
+	for _, output := range outputs {
+		if err := output.Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configx.TableConfigs.Outputs = outputs
+}
+
+func TestGroupOutputsManifest(t *testing.T) {
+	dir := chdirTemp(t)
+	writeTestFile(t, filepath.Join(dir, "schema.tmpl"), string(generatedMark)+"\n// {{.SchemaName}}:{{range .Tables}} {{.TableName}}{{end}}\n")
+	writeTestFile(t, filepath.Join(dir, "db.tmpl"), "// {{.DBName}}: {{len .Tables}} tables\n")
+	setOutputs(t,
+		&configx.OutputInfo{Template: "schema.tmpl", Path: "out/{{.SchemaName}}.txt", Scope: configx.OutputScopeSchema},
+		&configx.OutputInfo{Template: "db.tmpl", Path: "out/{{.DBName}}.txt", Scope: configx.OutputScopeDatabase},
+	)
+	dbInfo := &configx.DBTableInfo{DBName: "db"}
+	outputTables["public"] = []map[string]any{{"TableName": "user"}}
+	outputTables["sales"] = []map[string]any{{"TableName": "order"}}
+
+	WriteGroupOutputs(dbInfo)
+	want := map[string][]string{
+		"db:public.@schema_outputs": {"out/public.txt"},
+		"db:sales.@schema_outputs":  {"out/sales.txt"},
+		"db:@database_outputs":      {"out/db.txt"},
+	}
+	for key, files := range want {
+		entry, ok := manifest.Tables[key]
+		if !ok || !slices.Equal(entry.Files, files) {
+			t.Errorf("manifest[%s] = %+v, want files %q", key, entry, files)
+		}
+		if !seenTables[key] {
+			t.Errorf("%s should be marked as seen", key)
+		}
+	}
+	if len(renderedFiles) != 1 {
+		t.Errorf("renderedFiles = %q, want the database output", renderedFiles)
+	}
+
+	// 手工修改后 --check 报告过期
+	writeTestFile(t, filepath.Join(dir, "out", "sales.txt"), string(generatedMark)+"\n// edited\n")
+	genOptions.DryRun = true
+	WriteGroupOutputs(dbInfo)
+	if !slices.Equal(genReport.Modified, []string{filepath.Join(dir, "out", "sales.txt")}) {
+		t.Errorf("modified = %q, want the edited schema output", genReport.Modified)
+	}
+
+	// schema 被删除后，--prune 清理其范围输出
+	genOptions.DryRun = false
+	seenTables = map[string]bool{}
+	delete(outputTables, "sales")
+	WriteGroupOutputs(dbInfo)
+	pruneOrphans(dbInfo, map[string]map[string]string{"public": {"user": ""}})
+	if IsExist(filepath.Join(dir, "out", "sales.txt")) {
+		t.Error("output of the dropped schema should be pruned")
+	}
+	if _, ok := manifest.Tables["db:sales.@schema_outputs"]; ok {
+		t.Error("pruned schema outputs should be removed from the manifest")
+	}
+	for _, file := range []string{"out/public.txt", "out/db.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("%s should be kept: %v", file, err)
+		}
+	}
+}
+
+func TestWriteWithPolicy(t *testing.T) {
+	dir := chdirTemp(t)
+	resetManifest(t)
+	file := filepath.Join(dir, "out.txt")
+	writes := 0
+	write := func() error {
+		writes++
+		_, err := writeGenerated(file, []byte("generated\n"), 0644)
+		return err
+	}
+	check := func(step string, wantWrites int, wantContent string) {
+		t.Helper()
+		if writes != wantWrites {
+			t.Errorf("%s: %d writes, want %d", step, writes, wantWrites)
+		}
+		content, _ := os.ReadFile(file)
+		if string(content) != wantContent {
+			t.Errorf("%s: content = %q, want %q", step, content, wantContent)
+		}
+	}
+
+	// always：每次都写出
+	writeTestFile(t, file, "edited\n")
+	writeWithPolicy(configx.OverwriteAlways, file, write)
+	check("always", 1, "generated\n")
+
+	// never：已有文件保留，不存在时写出
+	writeTestFile(t, file, "edited\n")
+	renderedFiles = nil
+	writeWithPolicy(configx.OverwriteNever, file, write)
+	check("never with file", 1, "edited\n")
+	if !slices.Equal(renderedFiles, []string{file}) {
+		t.Errorf("kept file should still belong to the table, renderedFiles = %q", renderedFiles)
+	}
+	_ = os.Remove(file)
+	writeWithPolicy(configx.OverwriteNever, file, write)
+	check("never without file", 2, "generated\n")
+
+	// once：dry-run 时不记录，首次生成后记录，删除后不再生成
+	_ = os.Remove(file)
+	genOptions.DryRun = true
+	writeWithPolicy(configx.OverwriteOnce, file, write)
+	check("once in dry-run", 3, "")
+	if len(manifest.Once) != 0 {
+		t.Errorf("dry-run should not record once files, got %q", manifest.Once)
+	}
+	genOptions.DryRun = false
+	writeWithPolicy(configx.OverwriteOnce, file, write)
+	check("once", 4, "generated\n")
+	if !slices.Equal(manifest.Once, []string{"out.txt"}) {
+		t.Errorf("once files = %q, want out.txt", manifest.Once)
+	}
+	writeTestFile(t, file, "edited\n")
+	writeWithPolicy(configx.OverwriteOnce, file, write)
+	check("once with file", 4, "edited\n")
+	_ = os.Remove(file)
+	writeWithPolicy(configx.OverwriteOnce, file, write)
+	check("once after delete", 4, "")
+}
+
+// TestGeneratedTableOutputs table 范围的自定义输出按表渲染 model、dao 的数据，并随表记入生成清单
+func TestGeneratedTableOutputs(t *testing.T) {
+	tplFile := filepath.Join(t.TempDir(), "handler.tmpl")
+	writeTestFile(t, tplFile, string(generatedMark)+"\n\npackage api\n\n// {{.ModelStructName}}Handler {{.TableName}} in {{.DaoModulePath}}\n")
+	setOutputs(t, &configx.OutputInfo{Template: tplFile, Path: "api/{{.TableName}}_handler.go"})
+	dir := generateFromSQLite(t, &configx.DBTableInfo{},
+		"CREATE TABLE user (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE order_item (id INTEGER PRIMARY KEY)")
+
+	for table, want := range map[string]string{
+		"user":       "// UserHandler user in gentoltest/dal/db/dao\n",
+		"order_item": "// OrderItemHandler order_item in gentoltest/dal/db/dao\n",
+	} {
+		file := "api/" + table + "_handler.go"
+		if got := readGenerated(t, filepath.Join(dir, file)); !strings.HasSuffix(got, want) {
+			t.Errorf("%s = %q, want suffix %q", file, got, want)
+		}
+		var entry *ManifestEntry
+		for key, e := range manifest.Tables {
+			if strings.HasSuffix(key, ":"+table) {
+				entry = e
+			}
+		}
+		if entry == nil || !slices.Contains(entry.Files, file) {
+			t.Errorf("manifest of %s = %+v, want %s recorded", table, entry, file)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// pluginProtocolVersion 插件协议版本，请求、响应结构不兼容变更时递增
+const pluginProtocolVersion = 1
+
+// schemaTables 当前库读取到的全部表结构，供插件使用
+var schemaTables []*SchemaTable
+
+// PluginRequest 经 stdin 传给插件的请求
+type PluginRequest struct {
+	Version  int            `json:"version"`
+	Plugin   string         `json:"plugin"`
+	Options  any            `json:"options,omitempty"`
+	Database PluginDatabase `json:"database"`
+	Tables   []*SchemaTable `json:"tables"`
+}
+
+// PluginDatabase 当前库的生成配置
+type PluginDatabase struct {
+	DBName         string `json:"db_name"`
+	DBType         string `json:"db_type"`
+	ModelPath      string `json:"model_path"`
+	DaoPath        string `json:"dao_path"`
+	ModelModule    string `json:"model_module"`
+	DaoModule      string `json:"dao_module"`
+	GoModule       string `json:"go_module"`
+	JsonFormat     string `json:"json_format"`
+	UseSQLNullable bool   `json:"use_sql_nullable"`
+	TenantMode     string `json:"tenant_mode,omitempty"`
+}
+
+// PluginResponse 插件经 stdout 返回的响应
+type PluginResponse struct {
+	Files []*PluginFile `json:"files"`
+	Error string        `json:"error,omitempty"`
+}
+
+// PluginFile 插件生成的文件，path 相对当前目录
+type PluginFile struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Overwrite string `json:"overwrite,omitempty"` // always（默认）| once | never
+	Schema    string `json:"schema,omitempty"`    // 文件所属表的 schema，与请求中 tables 的 schema 一致
+	Table     string `json:"table,omitempty"`     // 文件所属表，表被删除后 --prune 清理该文件；为空时归属插件本身
+}
+
+// runPlugins 依次执行配置的插件，并按覆盖策略写出返回的文件
+func runPlugins(dbInfo *configx.DBTableInfo) {
+	sort.Slice(schemaTables, func(i, j int) bool {
+		if schemaTables[i].Schema != schemaTables[j].Schema {
+			return schemaTables[i].Schema < schemaTables[j].Schema
+		}
+		return schemaTables[i].Name < schemaTables[j].Name
+	})
+	for _, plugin := range configx.TableConfigs.Plugins {
+		if err := runPlugin(dbInfo, plugin); err != nil {
+			log.Printf("plugin %s failed: %v", plugin.Name, err)
+			genReport.Failed = append(genReport.Failed, "plugin:"+plugin.Name)
+		}
+	}
+}
+
+// runPlugin 执行单个插件
+func runPlugin(dbInfo *configx.DBTableInfo, plugin *configx.PluginInfo) error {
+	request, err := json.Marshal(&PluginRequest{
+		Version: pluginProtocolVersion,
+		Plugin:  plugin.Name,
+		Options: jsonCompatible(plugin.Options),
+		Database: PluginDatabase{
+			DBName:         dbInfo.DBName,
+			DBType:         dbInfo.DBType,
+			ModelPath:      dbInfo.ModelPath,
+			DaoPath:        dbInfo.DaoPath,
+			ModelModule:    dbInfo.ModelModule,
+			DaoModule:      dbInfo.DaoModule,
+			GoModule:       configx.TableConfigs.GoModule,
+			JsonFormat:     configx.TableConfigs.JsonFormat,
+			UseSQLNullable: dbInfo.UseSQLNullable,
+			TenantMode:     dbInfo.TenantMode,
+		},
+		Tables: schemaTables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(plugin.Executable())
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("run %s: %v", plugin.Executable(), err)
+	}
+
+	response := &PluginResponse{}
+	if err = json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("%s", response.Error)
+	}
+
+	// 先校验全部文件，避免部分写出
+	paths := make([]string, len(response.Files))
+	keys := make([]string, len(response.Files))
+	for i, file := range response.Files {
+		if paths[i], err = pluginFilePath(file.Path); err != nil {
+			return err
+		}
+		if keys[i], err = pluginManifestKey(dbInfo, plugin, file); err != nil {
+			return err
+		}
+		switch file.Overwrite {
+		case "":
+			file.Overwrite = configx.OverwriteAlways
+		case configx.OverwriteAlways, configx.OverwriteOnce, configx.OverwriteNever:
+		default:
+			return fmt.Errorf("%s: invalid overwrite %q", file.Path, file.Overwrite)
+		}
+	}
+
+	tpl := &metadata.Template{Name: "plugin:" + plugin.Name}
+	files := make(map[string][]string)
+	for i, file := range response.Files {
+		ff, content := paths[i], []byte(file.Content)
+		renderedFiles = nil
+		writeWithPolicy(file.Overwrite, ff, func() error {
+			ensureDir(filepath.Dir(ff))
+			if err := writeRendered(tpl, content, ff); err != nil {
+				genReport.Failed = append(genReport.Failed, ff)
+				return err
+			}
+			return nil
+		})
+		files[keys[i]] = append(files[keys[i]], renderedFiles...)
+	}
+
+	// 表的文件追加到表的生成记录中，随表一起清理；其余文件归属插件，插件从配置中移除后清理
+	pluginKey := manifestKey(dbInfo, "", pluginManifestName(plugin))
+	for key, keyFiles := range files {
+		if key != pluginKey {
+			manifest.addFiles(key, keyFiles)
+		}
+	}
+	if len(files[pluginKey]) > 0 {
+		seenTables[pluginKey] = true
+		manifest.record(pluginKey, "", files[pluginKey])
+	}
+	return nil
+}
+
+// pluginManifestName 插件自身生成的文件在生成清单中的名称
+func pluginManifestName(plugin *configx.PluginInfo) string {
+	return "@plugin_" + plugin.Name
+}
+
+// pluginManifestKey 文件在生成清单中的归属：指定了表时为本次处理过的表，否则为插件本身
+func pluginManifestKey(dbInfo *configx.DBTableInfo, plugin *configx.PluginInfo, file *PluginFile) (string, error) {
+	if file.Table == "" {
+		return manifestKey(dbInfo, "", pluginManifestName(plugin)), nil
+	}
+	key := manifestKey(dbInfo, file.Schema, file.Table)
+	if !seenTables[key] {
+		return "", fmt.Errorf("%s: unknown table %q", file.Path, buildFullTableName(file.Schema, file.Table))
+	}
+	return key, nil
+}
+
+// pluginFilePath 插件返回的路径必须位于当前目录内
+func pluginFilePath(path string) (string, error) {
+	if path == "" || filepath.IsAbs(path) {
+		return "", fmt.Errorf("plugin file path must be relative: %q", path)
+	}
+	clean := filepath.Clean(path)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("plugin file path escapes working directory: %q", path)
+	}
+	return filepath.Abs(clean)
+}
+
+// jsonCompatible 将 yaml 解析出的 map[interface{}]interface{} 转为可 JSON 序列化的结构
+func jsonCompatible(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = jsonCompatible(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = jsonCompatible(item)
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// writeTestPlugin 生成固定返回 response 的插件脚本，收到的请求保存在 dir/request.json
+func writeTestPlugin(t *testing.T, dir string, response *PluginResponse) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugin is not supported on windows")
+	}
+	content, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "response.json"), string(content))
+	script := filepath.Join(dir, "gentol-gen-test")
+	writeTestFile(t, script, "#!/bin/sh\ncat > '"+filepath.Join(dir, "request.json")+"'\ncat '"+filepath.Join(dir, "response.json")+"'\n")
+	if err = os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestPluginFilesManifest(t *testing.T) {
+	dir := chdirTemp(t)
+	resetManifest(t)
+	dbInfo := &configx.DBTableInfo{DBName: "db"}
+	mark := string(generatedMark) + "\n"
+	plugin := &configx.PluginInfo{Name: "test", Path: writeTestPlugin(t, t.TempDir(), &PluginResponse{Files: []*PluginFile{
+		{Path: "gen/all.txt", Content: mark + "all\n"},
+		{Path: "gen/user.txt", Content: mark + "user\n", Schema: "public", Table: "user"},
+	}})}
+
+	model := filepath.Join(dir, "model", "user.go")
+	writeTestFile(t, model, mark+"package model\n")
+	seenTables["db:public.user"] = true
+	manifest.record("db:public.user", "hash", []string{model})
+
+	if err := runPlugin(dbInfo, plugin); err != nil {
+		t.Fatal(err)
+	}
+	user := manifest.Tables["db:public.user"]
+	if !slices.Equal(user.Files, []string{"gen/user.txt", "model/user.go"}) || user.Hash != "hash" {
+		t.Errorf("user entry = %+v, want the plugin file appended and the fingerprint kept", user)
+	}
+	if !manifest.upToDate("db:public.user", "hash") {
+		t.Error("user should stay up to date after the plugin wrote its file")
+	}
+	if entry := manifest.Tables["db:@plugin_test"]; entry == nil || !slices.Equal(entry.Files, []string{"gen/all.txt"}) {
+		t.Errorf("plugin entry = %+v, want gen/all.txt", entry)
+	}
+
+	// 再次执行不重复记录
+	if err := runPlugin(dbInfo, plugin); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifest.Tables["db:public.user"].Files; len(got) != 2 {
+		t.Errorf("user files after rerun = %q", got)
+	}
+
+	// 表被删除后插件为其生成的文件一并清理，插件自身的文件保留
+	seenTables = map[string]bool{"db:@plugin_test": true}
+	pruneOrphans(dbInfo, map[string]map[string]string{"public": {"order": ""}})
+	if IsExist(filepath.Join(dir, "gen", "user.txt")) || IsExist(model) {
+		t.Error("files of the dropped table should be pruned")
+	}
+	if !IsExist(filepath.Join(dir, "gen", "all.txt")) {
+		t.Error("plugin file without a table should be kept")
+	}
+}
+
+func TestPluginUnknownTable(t *testing.T) {
+	chdirTemp(t)
+	resetManifest(t)
+	plugin := &configx.PluginInfo{Name: "test", Path: writeTestPlugin(t, t.TempDir(), &PluginResponse{Files: []*PluginFile{
+		{Path: "gen/ok.txt", Content: "ok\n"},
+		{Path: "gen/user.txt", Content: "user\n", Table: "user"},
+	}})}
+	err := runPlugin(&configx.DBTableInfo{DBName: "db"}, plugin)
+	if err == nil || !strings.Contains(err.Error(), `unknown table "user"`) {
+		t.Fatalf("runPlugin() error = %v, want unknown table", err)
+	}
+	if IsExist("gen/ok.txt") {
+		t.Error("no file should be written when the response is invalid")
+	}
+}
+
+func TestPluginFilePath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		"gen/a.txt":      filepath.Join(wd, "gen", "a.txt"),
+		"./gen/../b.txt": filepath.Join(wd, "b.txt"),
+		"..gen/c.txt":    filepath.Join(wd, "..gen", "c.txt"),
+	} {
+		if got, err := pluginFilePath(path); err != nil || got != want {
+			t.Errorf("pluginFilePath(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	for _, path := range []string{"", filepath.Join(wd, "a.txt"), "..", "../a.txt", "gen/../../a.txt"} {
+		if got, err := pluginFilePath(path); err == nil {
+			t.Errorf("pluginFilePath(%q) = %q, want an error", path, got)
+		}
+	}
+}
+
+func TestJSONCompatible(t *testing.T) {
+	value := map[string]any{
+		"out":   "api.yaml",
+		"paths": map[any]any{"v1": []any{map[any]any{1: true}}},
+	}
+	content, err := json.Marshal(jsonCompatible(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"out":"api.yaml","paths":{"v1":[{"1":true}]}}`; string(content) != want {
+		t.Errorf("jsonCompatible() = %s, want %s", content, want)
+	}
+}
+
+// TestGeneratedPluginRequest 插件经 stdin 收到库配置、参数及全部表结构，返回的文件按覆盖策略写出
+func TestGeneratedPluginRequest(t *testing.T) {
+	pluginDir := t.TempDir()
+	plugin := &configx.PluginInfo{
+		Name:    "test",
+		Options: map[string]any{"paths": map[any]any{"prefix": "/api"}},
+		Path: writeTestPlugin(t, pluginDir, &PluginResponse{Files: []*PluginFile{
+			{Path: "gen/user.txt", Content: "user\n", Table: "user"},
+			{Path: "gen/once.txt", Content: "once\n", Overwrite: configx.OverwriteOnce},
+		}}),
+	}
+	saved := configx.TableConfigs.Plugins
+	t.Cleanup(func() { configx.TableConfigs.Plugins = saved })
+	configx.TableConfigs.Plugins = []*configx.PluginInfo{plugin}
+
+	dir := generateFromSQLite(t, &configx.DBTableInfo{OnlyModel: true},
+		"CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)",
+		"CREATE TABLE person (id INTEGER PRIMARY KEY, first_name TEXT NOT NULL, "+
+			"name_len INTEGER GENERATED ALWAYS AS (length(first_name)) VIRTUAL)")
+
+	request := &PluginRequest{}
+	if err := json.Unmarshal([]byte(readGenerated(t, filepath.Join(pluginDir, "request.json"))), request); err != nil {
+		t.Fatal(err)
+	}
+	if request.Version != pluginProtocolVersion || request.Plugin != "test" {
+		t.Errorf("request version, plugin = %d, %s", request.Version, request.Plugin)
+	}
+	if options, _ := json.Marshal(request.Options); string(options) != `{"paths":{"prefix":"/api"}}` {
+		t.Errorf("request options = %s", options)
+	}
+	if db := request.Database; db.DBType != "sqlite" || db.ModelPath != "dal/db/model" || db.ModelModule != "gentoltest/dal/db/model" {
+		t.Errorf("request database = %+v", db)
+	}
+	if len(request.Tables) != 2 || request.Tables[0].Name != "person" || request.Tables[1].Name != "user" {
+		t.Fatalf("request tables = %+v, want person and user sorted", request.Tables)
+	}
+	person := request.Tables[0]
+	if person.StructName != "Person" || len(person.Columns) != 3 || !person.Columns[2].ReadOnly ||
+		!slices.Equal(person.PrimaryKey, []string{"id"}) {
+		t.Errorf("person = %+v, columns %+v", person, person.Columns)
+	}
+
+	for file, want := range map[string]string{"gen/user.txt": "user\n", "gen/once.txt": "once\n"} {
+		if got := readGenerated(t, filepath.Join(dir, file)); got != want {
+			t.Errorf("%s = %q, want %q", file, got, want)
+		}
+	}
+	if !slices.Equal(manifest.Once, []string{"gen/once.txt"}) {
+		t.Errorf("once files = %q, want gen/once.txt", manifest.Once)
+	}
+}
+
+func TestPluginErrors(t *testing.T) {
+	chdirTemp(t)
+	resetManifest(t)
+	dbInfo := &configx.DBTableInfo{DBName: "db"}
+	tests := []struct {
+		name     string
+		response *PluginResponse
+		want     string
+	}{
+		{"error response", &PluginResponse{Error: "boom"}, "boom"},
+		{"invalid overwrite", &PluginResponse{Files: []*PluginFile{{Path: "a.txt", Overwrite: "sometimes"}}}, `invalid overwrite "sometimes"`},
+		{"escaping path", &PluginResponse{Files: []*PluginFile{{Path: "../a.txt"}}}, "escapes working directory"},
+	}
+	for _, tt := range tests {
+		plugin := &configx.PluginInfo{Name: "test", Path: writeTestPlugin(t, t.TempDir(), tt.response)}
+		if err := runPlugin(dbInfo, plugin); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: runPlugin() = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	// 插件不存在、输出不是 JSON 时记为失败，不影响其他插件
+	script := filepath.Join(t.TempDir(), "gentol-gen-echo")
+	writeTestFile(t, script, "#!/bin/sh\necho not json\n")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	saved := configx.TableConfigs.Plugins
+	t.Cleanup(func() { configx.TableConfigs.Plugins = saved })
+	configx.TableConfigs.Plugins = []*configx.PluginInfo{
+		{Name: "missing", Path: filepath.Join(t.TempDir(), "gentol-gen-missing")},
+		{Name: "echo", Path: script},
+	}
+	genReport.Failed = nil
+	runPlugins(dbInfo)
+	if !slices.Equal(genReport.Failed, []string{"plugin:missing", "plugin:echo"}) {
+		t.Errorf("failed = %q, want both plugins", genReport.Failed)
+	}
+}
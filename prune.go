@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// seenTables 本次运行处理过的表（清单键），未出现且已从库中删除的表视为孤立表
+var seenTables = map[string]bool{}
+
+// generatedMark 覆盖生成文件的头部标记，见 metadata.NotEditMark
+var generatedMark = []byte("// Code generated by jasonlabz/gentol. DO NOT EDIT.")
+
+// pruneOrphans 删除库中已不存在的表遗留的生成文件；tables 为库中现有的表（schema -> 表名）
+func pruneOrphans(dbInfo *configx.DBTableInfo, tables map[string]map[string]string) {
+	if len(tables) == 0 {
+		log.Printf("未获取到库 %s 的表列表，跳过清理", dbInfo.DBName)
+		return
+	}
+	prefix := dbInfo.DBName + ":"
+	orphans := make([]string, 0)
+	for key := range manifest.Tables {
+		if !strings.HasPrefix(key, prefix) || seenTables[key] {
+			continue
+		}
+		schema, tableName, found := strings.Cut(strings.TrimPrefix(key, prefix), ".")
+		if !found {
+			schema, tableName = "", schema
+		}
+		if tableExists(dbInfo, tables, schema, tableName) {
+			continue
+		}
+		orphans = append(orphans, key)
+	}
+	if len(orphans) == 0 {
+		return
+	}
+	sort.Strings(orphans)
+
+	// 仍被其他表引用的公共文件（base.go、db.go）不删除
+	shared := make(map[string]bool)
+	for key, entry := range manifest.Tables {
+		if !isOrphan(orphans, key) {
+			for _, file := range entry.Files {
+				shared[file] = true
+			}
+		}
+	}
+
+	var editable []string
+	for _, key := range orphans {
+		log.Printf("表 %s 已不存在，清理其生成文件", key)
+		var kept []string
+		for _, file := range manifest.Tables[key].Files {
+			if shared[file] || !IsExist(file) {
+				continue
+			}
+			if !isGeneratedFile(file) {
+				editable = append(editable, file)
+				kept = append(kept, file)
+				continue
+			}
+			removeGenerated(file)
+		}
+		if genOptions.DryRun {
+			continue
+		}
+		if len(kept) == 0 {
+			delete(manifest.Tables, key)
+		} else {
+			// 保留未确认删除的可编辑文件，下次清理时再次提示
+			manifest.Tables[key].Files = kept
+		}
+	}
+	if len(editable) == 0 {
+		return
+	}
+	if genOptions.DryRun || !confirmRemove(editable) {
+		for _, file := range editable {
+			log.Printf("保留可编辑文件 %s", file)
+		}
+		return
+	}
+	for _, file := range editable {
+		removeGenerated(file)
+	}
+	for _, key := range orphans {
+		delete(manifest.Tables, key)
+	}
+}
+
+// isOrphan 判断清单键是否为孤立表
+func isOrphan(orphans []string, key string) bool {
+	idx := sort.SearchStrings(orphans, key)
+	return idx < len(orphans) && orphans[idx] == key
+}
+
+// tableExists 判断表（或分表的逻辑表）是否仍在库中
+func tableExists(dbInfo *configx.DBTableInfo, tables map[string]map[string]string, schema, tableName string) bool {
+	shard := dbInfo.GetShard(tableName)
+	for tableSchema, schemaTables := range tables {
+		if schema != "" && tableSchema != schema {
+			continue
+		}
+		if _, ok := schemaTables[tableName]; ok {
+			return true
+		}
+		if shard == nil {
+			continue
+		}
+		for physicalTable := range schemaTables {
+			if shard.Match(physicalTable) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isGeneratedFile 文件头部带有 NotEditMark 时为覆盖生成的文件
+func isGeneratedFile(file string) bool {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(content), generatedMark)
+}
+
+// removeGenerated 删除文件，dry-run 时只记录
+func removeGenerated(file string) {
+	genReport.Pruned = append(genReport.Pruned, file)
+	if genOptions.DryRun {
+		return
+	}
+	if err := os.Remove(file); err != nil {
+		log.Printf("remove %s failed: %v", file, err)
+		return
+	}
+	log.Printf("removing %s", file)
+}
+
+// confirmRemove 删除 _ext、_hook 等可编辑文件前需要在终端确认
+func confirmRemove(files []string) bool {
+	fmt.Println("the following files may contain hand-written code:")
+	for _, file := range files {
+		fmt.Printf("    %s\n", file)
+	}
+	fmt.Print("delete them as well? [y/N]: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// setStdin 以 input 作为终端输入，测试结束后还原
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	writeTestFile(t, path, input)
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		_ = file.Close()
+	})
+}
+
+// pruneFixture 记录 user、order、item、log 四张表及另一个库的生成文件，本次只处理了 user；返回库中现有的表
+func pruneFixture(t *testing.T) (*configx.DBTableInfo, map[string]map[string]string) {
+	t.Helper()
+	chdirTemp(t)
+	resetManifest(t)
+	generated := string(generatedMark) + "\n\npackage model\n"
+	for _, file := range []string{"model/base.go", "model/user.go", "model/order.go", "model/item.go", "model/log.go", "other/x.go"} {
+		writeTestFile(t, file, generated)
+	}
+	writeTestFile(t, "dao/order_dao_ext.go", "// Code generated by jasonlabz/gentol. You may edit it.\n\npackage dao\n")
+
+	manifest.record("db:public.user", "h", []string{"model/user.go", "model/base.go"})
+	manifest.record("db:public.order", "h", []string{"model/order.go", "model/base.go", "dao/order_dao_ext.go"})
+	manifest.record("db:public.item", "h", []string{"model/item.go"})
+	manifest.record("db:public.log", "h", []string{"model/log.go"})
+	manifest.record("other:public.x", "h", []string{"other/x.go"})
+	seenTables["db:public.user"] = true
+
+	dbInfo := &configx.DBTableInfo{DBName: "db", Shards: []*configx.ShardInfo{{TableName: "log", Pattern: `^log_\d{6}$`}}}
+	if err := dbInfo.CompileShards(); err != nil {
+		t.Fatal(err)
+	}
+	// order 已删除；item 未在本次处理（如被过滤）但仍存在；log 只剩物理分表
+	return dbInfo, map[string]map[string]string{"public": {"user": "", "item": "", "log_202401": ""}}
+}
+
+func TestPruneOrphans(t *testing.T) {
+	dbInfo, tables := pruneFixture(t)
+	setStdin(t, "n\n")
+	pruneOrphans(dbInfo, tables)
+
+	if IsExist("model/order.go") {
+		t.Error("generated file of the dropped table should be removed")
+	}
+	for _, file := range []string{"model/base.go", "model/user.go", "model/item.go", "model/log.go", "other/x.go"} {
+		if !IsExist(file) {
+			t.Errorf("%s should be kept", file)
+		}
+	}
+	if !IsExist("dao/order_dao_ext.go") {
+		t.Error("editable file should be kept when the removal is not confirmed")
+	}
+	if entry := manifest.Tables["db:public.order"]; entry == nil || !slices.Equal(entry.Files, []string{"dao/order_dao_ext.go"}) {
+		t.Errorf("manifest[db:public.order] = %+v, want the kept editable file only", entry)
+	}
+	for _, key := range []string{"db:public.user", "db:public.item", "db:public.log", "other:public.x"} {
+		if manifest.Tables[key] == nil {
+			t.Errorf("manifest[%s] should be kept", key)
+		}
+	}
+
+	// 再次清理时重新提示，确认后删除
+	setStdin(t, "y\n")
+	pruneOrphans(dbInfo, tables)
+	if IsExist("dao/order_dao_ext.go") {
+		t.Error("editable file should be removed once confirmed")
+	}
+	if _, ok := manifest.Tables["db:public.order"]; ok {
+		t.Error("pruned table should be removed from the manifest")
+	}
+}
+
+func TestPruneOrphansDryRun(t *testing.T) {
+	dbInfo, tables := pruneFixture(t)
+	genOptions.DryRun = true
+	genReport.Pruned = nil
+	// dry-run 不读取终端输入
+	setStdin(t, "y\n")
+	pruneOrphans(dbInfo, tables)
+
+	for _, file := range []string{"model/order.go", "dao/order_dao_ext.go"} {
+		if !IsExist(file) {
+			t.Errorf("dry-run should not remove %s", file)
+		}
+	}
+	if !slices.Equal(genReport.Pruned, []string{"model/order.go"}) {
+		t.Errorf("pruned = %q, want the generated file of the dropped table", genReport.Pruned)
+	}
+	if entry := manifest.Tables["db:public.order"]; entry == nil || len(entry.Files) != 3 {
+		t.Errorf("dry-run should not change the manifest, got %+v", entry)
+	}
+}
+
+func TestPruneOrphansWithoutTables(t *testing.T) {
+	dbInfo, _ := pruneFixture(t)
+	pruneOrphans(dbInfo, map[string]map[string]string{})
+	if !IsExist("model/order.go") || manifest.Tables["db:public.order"] == nil {
+		t.Error("prune without the table list of the database should keep everything")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"strings"
+)
+
+// 保护区标记：覆盖生成的文件中 begin/end 之间的内容在重新生成时保留
+const (
+	regionBegin = "// gentol:begin "
+	regionEnd   = "// gentol:end"
+)
+
+// region 文件中的一个保护区
+type region struct {
+	name         string
+	lines        []string // begin、end 之间的内容，保留行尾换行符
+	unterminated bool     // 缺少 end 标记，内容截止到文件末尾
+}
+
+// parseRegions 按出现顺序提取文件中的保护区；保护区内嵌套的 begin/end 作为内容保留，
+// 缺少 end 标记的保护区取到文件末尾，避免手写代码丢失
+func parseRegions(content []byte) []*region {
+	var (
+		regions []*region
+		current *region
+		depth   int
+	)
+	for _, line := range splitLines(content) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case current == nil && strings.HasPrefix(trimmed, regionBegin):
+			current = &region{name: strings.TrimSpace(strings.TrimPrefix(trimmed, regionBegin))}
+		case current != nil && trimmed == regionEnd && depth == 0:
+			regions = append(regions, current)
+			current = nil
+		case current != nil:
+			if strings.HasPrefix(trimmed, regionBegin) {
+				depth++
+			} else if trimmed == regionEnd {
+				depth--
+			}
+			current.lines = append(current.lines, line)
+		}
+	}
+	if current != nil {
+		current.unterminated = true
+		regions = append(regions, current)
+	}
+	return regions
+}
+
+// writeRegionLines 写出保护区内容，统一为生成文件的 \n 换行
+func writeRegionLines(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r") + "\n")
+	}
+}
+
+// spliceRegions 将已有文件保护区中的内容填回新渲染的内容；锚点已不存在的保护区追加到文件末尾并给出警告，避免手写代码丢失
+func spliceRegions(path string, rendered, existing []byte) []byte {
+	regions := parseRegions(existing)
+	if len(regions) == 0 {
+		return rendered
+	}
+	saved := make(map[string]*region, len(regions))
+	for _, r := range regions {
+		if r.unterminated {
+			log.Printf("warning: region %q in %s has no %q line, kept its content up to the end of file", r.name, path, regionEnd)
+		}
+		saved[r.name] = r
+	}
+
+	var (
+		buf     bytes.Buffer
+		skip    bool
+		spliced = make(map[string]bool)
+	)
+	for _, line := range splitLines(rendered) {
+		trimmed := strings.TrimSpace(line)
+		if skip {
+			if trimmed != regionEnd {
+				continue
+			}
+			skip = false
+		}
+		buf.WriteString(line)
+		if !strings.HasPrefix(trimmed, regionBegin) {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(trimmed, regionBegin))
+		r, ok := saved[name]
+		if !ok || spliced[name] {
+			continue
+		}
+		writeRegionLines(&buf, r.lines)
+		spliced[name] = true
+		skip = true
+	}
+
+	for _, r := range regions {
+		if spliced[r.name] || len(bytes.TrimSpace([]byte(strings.Join(r.lines, "")))) == 0 {
+			continue
+		}
+		log.Printf("warning: region %q in %s has no anchor in the regenerated file, moved to the end of file", r.name, path)
+		buf.WriteString("\n" + regionBegin + r.name + "\n")
+		writeRegionLines(&buf, r.lines)
+		buf.WriteString(regionEnd + "\n")
+	}
+	return buf.Bytes()
+}
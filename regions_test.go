@@ -0,0 +1,117 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []*region
+	}{
+		{"none", "package a\n", nil},
+		{
+			"two regions",
+			"a\n\t// gentol:begin imports\n\t\"fmt\"\n\t// gentol:end\nb\n// gentol:begin custom\n// gentol:end\n",
+			[]*region{{name: "imports", lines: []string{"\t\"fmt\"\n"}}, {name: "custom"}},
+		},
+		{
+			"nested begin is content",
+			"// gentol:begin outer\nx\n// gentol:begin inner\ny\n// gentol:end\nz\n// gentol:end\nafter\n",
+			[]*region{{name: "outer", lines: []string{"x\n", "// gentol:begin inner\n", "y\n", "// gentol:end\n", "z\n"}}},
+		},
+		{
+			"unterminated",
+			"a\n// gentol:begin custom\nfunc f() {}\nfunc g() {}",
+			[]*region{{name: "custom", lines: []string{"func f() {}\n", "func g() {}"}, unterminated: true}},
+		},
+		{
+			"stray end",
+			"// gentol:end\n// gentol:begin custom\nx\n// gentol:end\n",
+			[]*region{{name: "custom", lines: []string{"x\n"}}},
+		},
+		{
+			"crlf",
+			"a\r\n// gentol:begin custom\r\nx\r\n// gentol:end\r\n",
+			[]*region{{name: "custom", lines: []string{"x\r\n"}}},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseRegions([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseRegions() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpliceRegions(t *testing.T) {
+	const rendered = "package a\n\nimport (\n\t// gentol:begin imports\n\t// gentol:end\n)\n\nfunc A() {}\n\n// gentol:begin custom\n// default\n// gentol:end\n"
+	tests := []struct {
+		name     string
+		rendered string
+		existing string
+		want     string
+	}{
+		{
+			name:     "no existing regions",
+			rendered: rendered,
+			existing: "package a\n",
+			want:     rendered,
+		},
+		{
+			name:     "keep region content",
+			rendered: rendered,
+			existing: "package a\n\nimport (\n\t// gentol:begin imports\n\t\"fmt\"\n\t// gentol:end\n)\n\nfunc Old() {}\n\n// gentol:begin custom\nfunc B() { fmt.Println() }\n// gentol:end\n",
+			want:     "package a\n\nimport (\n\t// gentol:begin imports\n\t\"fmt\"\n\t// gentol:end\n)\n\nfunc A() {}\n\n// gentol:begin custom\nfunc B() { fmt.Println() }\n// gentol:end\n",
+		},
+		{
+			name:     "emptied region drops the default content",
+			rendered: rendered,
+			existing: "// gentol:begin custom\n// gentol:end\n",
+			want:     "package a\n\nimport (\n\t// gentol:begin imports\n\t// gentol:end\n)\n\nfunc A() {}\n\n// gentol:begin custom\n// gentol:end\n",
+		},
+		{
+			name:     "moved anchor",
+			rendered: "// gentol:begin custom\n// gentol:end\nfunc A() {}\n",
+			existing: "func A() {}\n// gentol:begin custom\nfunc B() {}\n// gentol:end\n",
+			want:     "// gentol:begin custom\nfunc B() {}\n// gentol:end\nfunc A() {}\n",
+		},
+		{
+			name:     "removed anchor is appended",
+			rendered: "func A() {}\n",
+			existing: "func A() {}\n// gentol:begin custom\nfunc B() {}\n// gentol:end\n// gentol:begin empty\n\n// gentol:end\n",
+			want:     "func A() {}\n\n// gentol:begin custom\nfunc B() {}\n// gentol:end\n",
+		},
+		{
+			name:     "nested region",
+			rendered: "// gentol:begin custom\n// gentol:end\nfunc A() {}\n",
+			existing: "// gentol:begin custom\nx\n// gentol:begin inner\ny\n// gentol:end\nz\n// gentol:end\n",
+			want:     "// gentol:begin custom\nx\n// gentol:begin inner\ny\n// gentol:end\nz\n// gentol:end\nfunc A() {}\n",
+		},
+		{
+			name:     "unterminated region keeps content to end of file",
+			rendered: "func A() {}\n// gentol:begin custom\n// gentol:end\n",
+			existing: "func A() {}\n// gentol:begin custom\nfunc B() {}",
+			want:     "func A() {}\n// gentol:begin custom\nfunc B() {}\n// gentol:end\n",
+		},
+		{
+			name:     "crlf existing file",
+			rendered: "func A() {}\n// gentol:begin custom\n// gentol:end\n",
+			existing: "func A() {}\r\n// gentol:begin custom\r\nfunc B() {}\r\n\r\n// gentol:end\r\n",
+			want:     "func A() {}\n// gentol:begin custom\nfunc B() {}\n\n// gentol:end\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(spliceRegions("a.go", []byte(tt.rendered), []byte(tt.existing)))
+			if got != tt.want {
+				t.Errorf("spliceRegions() =\n%q\nwant\n%q", got, tt.want)
+			}
+			// 再次拼接结果保持不变
+			if again := string(spliceRegions("a.go", []byte(tt.rendered), []byte(got))); again != got {
+				t.Errorf("splicing again =\n%q\nwant\n%q", again, got)
+			}
+		})
+	}
+}
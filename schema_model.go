@@ -0,0 +1,116 @@
+package main
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/gormx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// SchemaTable 读取到的表结构，插件协议及 inspect 输出共用，字段名保持稳定
+type SchemaTable struct {
+	Schema     string          `json:"schema" yaml:"schema"`
+	Name       string          `json:"name" yaml:"name"`
+	Source     string          `json:"source,omitempty" yaml:"source,omitempty"` // 分表时读取字段结构的物理表
+	Comment    string          `json:"comment,omitempty" yaml:"comment,omitempty"`
+	StructName string          `json:"struct_name" yaml:"struct_name"`
+	FileName   string          `json:"file_name" yaml:"file_name"`
+	PrimaryKey []string        `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	Columns    []*SchemaColumn `json:"columns" yaml:"columns"`
+	Indexes    []*SchemaIndex  `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+}
+
+// SchemaColumn 字段结构
+type SchemaColumn struct {
+	Name           string  `json:"name" yaml:"name"`
+	ColumnType     string  `json:"column_type" yaml:"column_type"`           // 数据库原始类型，如 varchar(64)
+	DatabaseType   string  `json:"database_type" yaml:"database_type"`       // 数据库类型名，如 varchar
+	GoType         string  `json:"go_type" yaml:"go_type"`                   // 映射的 Go 类型
+	NullableGoType string  `json:"nullable_go_type" yaml:"nullable_go_type"` // 可空时使用的 Go 类型
+	Length         int64   `json:"length,omitempty" yaml:"length,omitempty"`
+	Precision      int64   `json:"precision,omitempty" yaml:"precision,omitempty"`
+	Scale          int64   `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Nullable       bool    `json:"nullable" yaml:"nullable"`
+	PrimaryKey     bool    `json:"primary_key" yaml:"primary_key"`
+	Unique         bool    `json:"unique" yaml:"unique"`
+	AutoIncrement  bool    `json:"auto_increment" yaml:"auto_increment"`
+	ReadOnly       bool    `json:"read_only" yaml:"read_only"` // 生成列、计算列
+	Default        *string `json:"default,omitempty" yaml:"default,omitempty"`
+	Comment        string  `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// SchemaIndex 索引结构
+type SchemaIndex struct {
+	Name       string   `json:"name" yaml:"name"`
+	Columns    []string `json:"columns" yaml:"columns"`
+	Unique     bool     `json:"unique" yaml:"unique"`
+	PrimaryKey bool     `json:"primary_key" yaml:"primary_key"`
+	Option     string   `json:"option,omitempty" yaml:"option,omitempty"`
+}
+
+// newSchemaTable 由读取到的字段、索引构建表结构
+func newSchemaTable(dbInfo *configx.DBTableInfo, schema, tableName, sourceTable, tableComment string,
+	columnTypes []gorm.ColumnType, indexes []gorm.Index, readOnlyColumns []string) *SchemaTable {
+	structName := dbInfo.Naming.StructName(tableName)
+	table := &SchemaTable{
+		Schema:     schema,
+		Name:       tableName,
+		Comment:    tableComment,
+		StructName: structName,
+		FileName:   dbInfo.Naming.FileName(tableName, structName),
+		Columns:    make([]*SchemaColumn, 0, len(columnTypes)),
+		Indexes:    make([]*SchemaIndex, 0, len(indexes)),
+	}
+	if sourceTable != tableName {
+		table.Source = sourceTable
+	}
+
+	columnInfoList := make([]*metadata.ColumnInfo, 0, len(columnTypes))
+	getColumnInfo(columnTypes, &columnInfoList)
+	markReadOnlyColumns(columnInfoList, readOnlyColumns)
+	for i, columnInfo := range columnInfoList {
+		metaType := metadata.GetMetaType(gormx.DBType(dbInfo.DBType), columnInfo.DataBaseType)
+		nullableType := metaType.GureguNullableType
+		if dbInfo.UseSQLNullable {
+			nullableType = metaType.SQLNullableType
+		}
+		column := &SchemaColumn{
+			Name:           columnInfo.ColumnName,
+			ColumnType:     columnInfo.ColumnType,
+			DatabaseType:   columnInfo.DataBaseType,
+			GoType:         metaType.GoType,
+			NullableGoType: nullableType,
+			Length:         columnInfo.Length,
+			Nullable:       columnInfo.Nullable,
+			PrimaryKey:     columnInfo.IsPrimaryKey,
+			Unique:         columnInfo.Unique,
+			AutoIncrement:  columnInfo.AutoIncrement,
+			ReadOnly:       columnInfo.ReadOnly,
+			Comment:        columnInfo.Comment,
+		}
+		if precision, scale, ok := columnTypes[i].DecimalSize(); ok {
+			column.Precision, column.Scale = precision, scale
+		}
+		if defaultValue, ok := columnTypes[i].DefaultValue(); ok {
+			column.Default = &defaultValue
+		}
+		if column.PrimaryKey {
+			table.PrimaryKey = append(table.PrimaryKey, column.Name)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	for _, index := range indexes {
+		unique, _ := index.Unique()
+		primaryKey, _ := index.PrimaryKey()
+		table.Indexes = append(table.Indexes, &SchemaIndex{
+			Name:       index.Name(),
+			Columns:    index.Columns(),
+			Unique:     unique,
+			PrimaryKey: primaryKey,
+			Option:     index.Option(),
+		})
+	}
+	return table
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/datasource"
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// schemaSource 表结构来源：在线数据库、离线的 schema 快照或建表 SQL
+type schemaSource interface {
+	// tables 库中全部表：schema -> 表名 -> 注释
+	tables() (map[string]map[string]string, error)
+	// table 读取表的字段、索引及只读字段
+	table(schema, tableName string) ([]gorm.ColumnType, []gorm.Index, []string, error)
+}
+
+// newSchemaSource 指定 --from-snapshot 时从快照读取表结构，指定 --from-sql 时解析建表 SQL，否则连接数据库
+func newSchemaSource(dbInfo *configx.DBTableInfo) schemaSource {
+	if genOptions.Snapshot != "" {
+		source, err := loadSnapshotSource(dbInfo, genOptions.Snapshot)
+		if err != nil {
+			panic(err)
+		}
+		return source
+	}
+	if genOptions.SQLPath != "" {
+		source, err := loadSQLSource(dbInfo, genOptions.SQLPath)
+		if err != nil {
+			panic(err)
+		}
+		return source
+	}
+	return &dbSource{
+		dbInfo: dbInfo,
+		db:     createDBConnection(dbInfo),
+		ds:     getDataSource(gormx.DBType(dbInfo.DBType)),
+	}
+}
+
+// dbSource 从在线数据库读取表结构
+type dbSource struct {
+	dbInfo *configx.DBTableInfo
+	db     *gorm.DB
+	ds     *datasource.DS
+}
+
+func (s *dbSource) tables() (map[string]map[string]string, error) {
+	dbTableMap, err := s.ds.GetTablesUnderDB(context.TODO(), s.dbInfo.DBName)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]map[string]string, len(dbTableMap))
+	for schema, dbMeta := range dbTableMap {
+		tables[schema] = make(map[string]string, len(dbMeta.TableInfoList))
+		for _, table := range dbMeta.TableInfoList {
+			tables[schema][table.TableName] = table.Comment
+		}
+	}
+	return tables, nil
+}
+
+// table 索引、只读字段读取失败时仅打印日志
+func (s *dbSource) table(schema, tableName string) ([]gorm.ColumnType, []gorm.Index, []string, error) {
+	fullTableName := buildFullTableName(schema, tableName)
+	columnTypes, err := s.db.Migrator().ColumnTypes(fullTableName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("获取表 %s 列信息失败: %v", fullTableName, err)
+	}
+	indexes, err := s.db.Migrator().GetIndexes(fullTableName)
+	if err != nil {
+		log.Println(err)
+	}
+	readOnlyColumns, err := s.ds.GetReadOnlyColumns(context.TODO(), s.dbInfo.DBName, schema, tableName)
+	if err != nil {
+		log.Printf("获取表 %s 只读字段失败: %v", fullTableName, err)
+	}
+	return columnTypes, indexes, readOnlyColumns, nil
+}
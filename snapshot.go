@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+
+	"github.com/jasonlabz/gentol/configx"
+)
+
+// snapshotSource 从 gentol inspect 导出的 schema 快照读取表结构，不连接数据库
+type snapshotSource struct {
+	database *InspectDatabase
+	tableMap map[string]*SchemaTable // 完整表名 -> 表结构
+}
+
+// loadSnapshotSource 读取快照中与 dbInfo 同名的库；快照只有一个库时直接使用
+func loadSnapshotSource(dbInfo *configx.DBTableInfo, path string) (*snapshotSource, error) {
+	result, err := loadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	var database *InspectDatabase
+	for _, item := range result.Databases {
+		if item.DBName == dbInfo.DBName {
+			database = item
+			break
+		}
+	}
+	if database == nil && len(result.Databases) == 1 {
+		database = result.Databases[0]
+	}
+	if database == nil {
+		return nil, fmt.Errorf("snapshot %s has no database %s", path, dbInfo.DBName)
+	}
+
+	// 字段的原始类型来自快照，类型映射以快照的数据库类型为准
+	if database.DBType != "" && database.DBType != dbInfo.DBType {
+		log.Printf("使用快照的数据库类型 %s（配置为 %s）", database.DBType, dbInfo.DBType)
+		dbInfo.DBType = database.DBType
+	}
+
+	log.Printf("从快照 %s 读取库 %s 的表结构", path, database.DBName)
+	return newSnapshotSource(database), nil
+}
+
+// newSnapshotSource 以库的表结构作为表结构来源
+func newSnapshotSource(database *InspectDatabase) *snapshotSource {
+	source := &snapshotSource{database: database, tableMap: make(map[string]*SchemaTable)}
+	for _, schema := range database.Schemas {
+		for _, table := range schema.Tables {
+			source.tableMap[buildFullTableName(schema.Name, table.Name)] = table
+		}
+	}
+	return source
+}
+
+// loadSnapshot 读取快照文件，.yaml/.yml 按 YAML 解析，其余按 JSON 解析
+func loadSnapshot(path string) (*InspectResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &InspectResult{}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(content, result)
+	} else {
+		err = json.Unmarshal(content, result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %v", path, err)
+	}
+	if result.Version > inspectVersion {
+		return nil, fmt.Errorf("snapshot %s version %d is newer than supported version %d, upgrade gentol",
+			path, result.Version, inspectVersion)
+	}
+	return result, nil
+}
+
+func (s *snapshotSource) tables() (map[string]map[string]string, error) {
+	tables := make(map[string]map[string]string, len(s.database.Schemas))
+	for _, schema := range s.database.Schemas {
+		if _, ok := tables[schema.Name]; !ok {
+			tables[schema.Name] = make(map[string]string, len(schema.Tables))
+		}
+		for _, table := range schema.Tables {
+			tables[schema.Name][table.Name] = table.Comment
+		}
+	}
+	return tables, nil
+}
+
+func (s *snapshotSource) table(schema, tableName string) ([]gorm.ColumnType, []gorm.Index, []string, error) {
+	fullTableName := buildFullTableName(schema, tableName)
+	table, ok := s.tableMap[fullTableName]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("表 %s 不在快照中", fullTableName)
+	}
+
+	columnTypes := make([]gorm.ColumnType, 0, len(table.Columns))
+	readOnlyColumns := make([]string, 0)
+	for _, column := range table.Columns {
+		columnType := migrator.ColumnType{
+			NameValue:          sql.NullString{String: column.Name, Valid: true},
+			DataTypeValue:      sql.NullString{String: column.DatabaseType, Valid: true},
+			ColumnTypeValue:    sql.NullString{String: column.ColumnType, Valid: true},
+			PrimaryKeyValue:    sql.NullBool{Bool: column.PrimaryKey, Valid: true},
+			UniqueValue:        sql.NullBool{Bool: column.Unique, Valid: true},
+			AutoIncrementValue: sql.NullBool{Bool: column.AutoIncrement, Valid: true},
+			LengthValue:        sql.NullInt64{Int64: column.Length, Valid: true},
+			DecimalSizeValue:   sql.NullInt64{Int64: column.Precision, Valid: true},
+			ScaleValue:         sql.NullInt64{Int64: column.Scale, Valid: true},
+			NullableValue:      sql.NullBool{Bool: column.Nullable, Valid: true},
+			ScanTypeValue:      reflect.TypeOf((*any)(nil)).Elem(),
+			CommentValue:       sql.NullString{String: column.Comment, Valid: true},
+		}
+		if column.Default != nil {
+			columnType.DefaultValueValue = sql.NullString{String: *column.Default, Valid: true}
+		}
+		columnTypes = append(columnTypes, columnType)
+		if column.ReadOnly {
+			readOnlyColumns = append(readOnlyColumns, column.Name)
+		}
+	}
+
+	indexes := make([]gorm.Index, 0, len(table.Indexes))
+	for _, index := range table.Indexes {
+		indexes = append(indexes, migrator.Index{
+			TableName:       table.Name,
+			NameValue:       index.Name,
+			ColumnList:      index.Columns,
+			PrimaryKeyValue: sql.NullBool{Bool: index.PrimaryKey, Valid: true},
+			UniqueValue:     sql.NullBool{Bool: index.Unique, Valid: true},
+			OptionValue:     index.Option,
+		})
+	}
+	return columnTypes, indexes, readOnlyColumns, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// SQL 词法单元类型
+const (
+	tokWord   = iota // 关键字、未加引号的标识符
+	tokIdent         // 加引号的标识符
+	tokString        // 字符串字面量
+	tokNumber        // 数字
+	tokPunct         // 符号
+)
+
+// sqlToken SQL 词法单元，text 为去掉引号、转义后的值，raw 为原文
+type sqlToken struct {
+	kind int
+	text string
+	raw  string
+}
+
+// is 判断是否为指定关键字（不区分大小写）
+func (t sqlToken) is(word string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, word)
+}
+
+// splitStatements 将 SQL 拆分为语句，每条语句为一组词法单元；注释被丢弃，字符串、引号标识符中的分号不会断句
+func splitStatements(content string, dbType gormx.DBType) [][]sqlToken {
+	var (
+		statements [][]sqlToken
+		current    []sqlToken
+	)
+	// MySQL 中双引号默认为字符串，其他数据库为标识符
+	doubleQuoteString := dbType == gormx.DBTypeMySQL
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && strings.HasPrefix(content[i:], "--"),
+			c == '#' && dbType == gormx.DBTypeMySQL:
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 4
+			}
+		case c == ';':
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			i++
+		case c == '\'' || (c == '"' && doubleQuoteString):
+			text, end := scanQuoted(content, i, c, dbType == gormx.DBTypeMySQL)
+			current = append(current, sqlToken{kind: tokString, text: text, raw: content[i:end]})
+			i = end
+		case c == '"' || c == '`':
+			text, end := scanQuoted(content, i, c, false)
+			current = append(current, sqlToken{kind: tokIdent, text: text, raw: content[i:end]})
+			i = end
+		case c == '$' && dbType != gormx.DBTypeMySQL && isDollarQuote(content[i:]):
+			tag := content[i : i+strings.Index(content[i+1:], "$")+2]
+			end := strings.Index(content[i+len(tag):], tag)
+			if end < 0 {
+				end = len(content)
+			} else {
+				end += i + 2*len(tag)
+			}
+			current = append(current, sqlToken{kind: tokString, text: content[i+len(tag) : max(end-len(tag), i+len(tag))], raw: content[i:end]})
+			i = end
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < len(content) && content[i+1] >= '0' && content[i+1] <= '9'):
+			start := i
+			for i < len(content) && (content[i] >= '0' && content[i] <= '9' || content[i] == '.') {
+				i++
+			}
+			current = append(current, sqlToken{kind: tokNumber, text: content[start:i], raw: content[start:i]})
+		case isWordByte(c):
+			start := i
+			for i < len(content) && (isWordByte(content[i]) || content[i] >= '0' && content[i] <= '9' || content[i] == '$') {
+				i++
+			}
+			if i < len(content) && content[i] == '\'' && isStringPrefix(content[start:i], dbType) {
+				// b'0'、x'ff'、N'中文'、E'\n'、_utf8mb4'x' 等带前缀的字符串作为一个词法单元
+				prefix := strings.ToLower(content[start:i])
+				text, end := scanQuoted(content, i, '\'', dbType == gormx.DBTypeMySQL || prefix == "e")
+				current = append(current, sqlToken{kind: tokString, text: text, raw: content[start:end]})
+				i = end
+				continue
+			}
+			current = append(current, sqlToken{kind: tokWord, text: content[start:i], raw: content[start:i]})
+		case c == ':' && strings.HasPrefix(content[i:], "::"),
+			(c == '<' || c == '>' || c == '!') && i+1 < len(content) && (content[i+1] == '=' || c == '<' && content[i+1] == '>'):
+			current = append(current, sqlToken{kind: tokPunct, text: content[i : i+2], raw: content[i : i+2]})
+			i += 2
+		default:
+			current = append(current, sqlToken{kind: tokPunct, text: string(c), raw: string(c)})
+			i++
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements
+}
+
+// scanQuoted 读取引号包围的内容，连续两个引号表示引号本身；backslash 为 true 时支持反斜杠转义
+func scanQuoted(content string, start int, quote byte, backslash bool) (string, int) {
+	var sb strings.Builder
+	i := start + 1
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case backslash && c == '\\' && i+1 < len(content):
+			sb.WriteByte(unescapeByte(content[i+1]))
+			i += 2
+			continue
+		case c == quote && i+1 < len(content) && content[i+1] == quote:
+			sb.WriteByte(quote)
+			i += 2
+			continue
+		case c == quote:
+			return sb.String(), i + 1
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	return sb.String(), i
+}
+
+func unescapeByte(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+// isDollarQuote 判断是否为 PostgreSQL 的 $tag$ 字符串起始
+func isDollarQuote(s string) bool {
+	end := strings.Index(s[1:], "$")
+	if end < 0 {
+		return false
+	}
+	for i := 1; i <= end; i++ {
+		if !isWordByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isStringPrefix 判断是否为紧贴字符串的前缀：位串 b、十六进制 x、国家字符集 n、PostgreSQL 转义串 e、MySQL 字符集 _charset
+func isStringPrefix(word string, dbType gormx.DBType) bool {
+	switch strings.ToLower(word) {
+	case "b", "x", "n":
+		return true
+	case "e":
+		return dbType != gormx.DBTypeMySQL
+	}
+	return dbType == gormx.DBTypeMySQL && len(word) > 1 && word[0] == '_'
+}
+
+// isBitString 判断是否为 b'0101'、x'ff' 形式的位串、十六进制字面量，此时原文才是值本身
+func isBitString(token sqlToken) bool {
+	return token.kind == tokString && len(token.raw) > 1 && token.raw[1] == '\'' && strings.ContainsRune("bBxX", rune(token.raw[0]))
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// joinTokens 按 information_schema 的书写习惯拼接词法单元，括号、逗号、点号及 :: 两侧不加空格
+func joinTokens(tokens []sqlToken) string {
+	var sb strings.Builder
+	for i, token := range tokens {
+		if i > 0 {
+			prev, raw := tokens[i-1].raw, token.raw
+			switch {
+			case raw == "(" && tokens[i-1].kind == tokWord,
+				raw == ")", raw == ",", raw == ".", raw == "::", raw == "[", raw == "]",
+				prev == "(", prev == ",", prev == ".", prev == "::", prev == "[":
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(token.raw)
+	}
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  gormx.DBType
+		content string
+		want    []string
+	}{
+		{
+			name:    "line and block comments",
+			dbType:  gormx.DBTypePostgres,
+			content: "-- drop table a;\nCREATE TABLE a (id int); /* ; */ DROP TABLE b -- trailing;\n;",
+			want:    []string{"CREATE TABLE a(id int)", "DROP TABLE b"},
+		},
+		{
+			name:    "mysql hash comment",
+			dbType:  gormx.DBTypeMySQL,
+			content: "# setup; skipped\nSET NAMES utf8mb4;",
+			want:    []string{"SET NAMES utf8mb4"},
+		},
+		{
+			name:    "semicolon in string and quoted identifier",
+			dbType:  gormx.DBTypePostgres,
+			content: `COMMENT ON TABLE "a;b" IS 'x;y'; SELECT 1`,
+			want:    []string{`COMMENT ON TABLE "a;b" IS 'x;y'`, "SELECT 1"},
+		},
+		{
+			name:    "doubled quotes",
+			dbType:  gormx.DBTypePostgres,
+			content: "SELECT 'it''s; fine'; SELECT 2",
+			want:    []string{"SELECT 'it''s; fine'", "SELECT 2"},
+		},
+		{
+			name:    "dollar quoted function body",
+			dbType:  gormx.DBTypePostgres,
+			content: "CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql; SELECT 1",
+			want:    []string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			name:    "tagged dollar quote",
+			dbType:  gormx.DBTypePostgres,
+			content: "DO $body$ BEGIN PERFORM '$$;'; END $body$; SELECT 1",
+			want:    []string{"DO $body$ BEGIN PERFORM '$$;'; END $body$", "SELECT 1"},
+		},
+		{
+			name:    "mysql backslash escape",
+			dbType:  gormx.DBTypeMySQL,
+			content: `INSERT INTO t VALUES ('it\'s; ok'); SELECT 1`,
+			want:    []string{`INSERT INTO t VALUES('it\'s; ok')`, "SELECT 1"},
+		},
+		{
+			name:    "postgres backslash is literal",
+			dbType:  gormx.DBTypePostgres,
+			content: `SELECT 'a\'; SELECT 2`,
+			want:    []string{`SELECT 'a\'`, "SELECT 2"},
+		},
+		{
+			name:    "prefixed literals",
+			dbType:  gormx.DBTypeMySQL,
+			content: "CREATE TABLE t (a bit(1) DEFAULT b'0', b varchar(8) DEFAULT _utf8mb4'x;y')",
+			want:    []string{"CREATE TABLE t(a bit(1) DEFAULT b'0',b varchar(8) DEFAULT _utf8mb4'x;y')"},
+		},
+		{
+			name:    "operators",
+			dbType:  gormx.DBTypePostgres,
+			content: "SELECT a::text WHERE a <> b AND c >= d",
+			want:    []string{"SELECT a::text WHERE a <> b AND c >= d"},
+		},
+		{
+			name:    "unterminated string",
+			dbType:  gormx.DBTypePostgres,
+			content: "SELECT 'abc; SELECT 2",
+			want:    []string{"SELECT 'abc; SELECT 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, statement := range splitStatements(tt.content, tt.dbType) {
+				got = append(got, joinTokens(statement))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  gormx.DBType
+		content string
+		want    sqlToken
+	}{
+		{"mysql escapes", gormx.DBTypeMySQL, `'a\'b\nc\\'`, sqlToken{kind: tokString, text: "a'b\nc\\", raw: `'a\'b\nc\\'`}},
+		{"mysql double quoted string", gormx.DBTypeMySQL, `"a""b"`, sqlToken{kind: tokString, text: `a"b`, raw: `"a""b"`}},
+		{"postgres double quoted identifier", gormx.DBTypePostgres, `"User"`, sqlToken{kind: tokIdent, text: "User", raw: `"User"`}},
+		{"backtick identifier", gormx.DBTypeMySQL, "`order`", sqlToken{kind: tokIdent, text: "order", raw: "`order`"}},
+		{"dollar string", gormx.DBTypePostgres, "$$it's$$", sqlToken{kind: tokString, text: "it's", raw: "$$it's$$"}},
+		{"bit string", gormx.DBTypeMySQL, "b'0'", sqlToken{kind: tokString, text: "0", raw: "b'0'"}},
+		{"hex string", gormx.DBTypePostgres, "X'FF'", sqlToken{kind: tokString, text: "FF", raw: "X'FF'"}},
+		{"national string", gormx.DBTypeSqlserver, "N'名称'", sqlToken{kind: tokString, text: "名称", raw: "N'名称'"}},
+		{"postgres escape string", gormx.DBTypePostgres, `E'a\'b'`, sqlToken{kind: tokString, text: "a'b", raw: `E'a\'b'`}},
+		{"number", gormx.DBTypePostgres, "3.14", sqlToken{kind: tokNumber, text: "3.14", raw: "3.14"}},
+		{"word with dollar", gormx.DBTypePostgres, "a$1", sqlToken{kind: tokWord, text: "a$1", raw: "a$1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := splitStatements(tt.content, tt.dbType)
+			if len(statements) != 1 || len(statements[0]) != 1 {
+				t.Fatalf("splitStatements(%q) = %v, want a single token", tt.content, statements)
+			}
+			if got := statements[0][0]; got != tt.want {
+				t.Errorf("token = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
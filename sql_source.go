@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/gormx"
+)
+
+// loadSQLSource 解析 path 中的建表 SQL 得到表结构，方言由 db_type 决定；目录下的 .sql 文件按迁移顺序依次解析
+func loadSQLSource(dbInfo *configx.DBTableInfo, path string) (*snapshotSource, error) {
+	parser, err := newDDLParser(gormx.DBType(dbInfo.DBType))
+	if err != nil {
+		return nil, err
+	}
+	files, err := sqlFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		parser.parse(file, string(content))
+	}
+	for _, warning := range parser.warnings {
+		log.Printf("warning: %s", warning)
+	}
+	log.Printf("从 %s 的 %d 个 SQL 文件解析出 %d 张表", path, len(files), len(parser.order))
+	return newSnapshotSource(parser.database(dbInfo.DBName, dbInfo.DBType)), nil
+}
+
+// sqlFiles path 为文件时直接返回；为目录时返回其中的 .sql 文件，跳过 .down.sql 回滚脚本，
+// 按文件名中的数字自然排序，V2__x.sql 排在 V10__x.sql 之前
+func sqlFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := strings.ToLower(d.Name())
+		if !d.IsDir() && strings.HasSuffix(name, ".sql") && !strings.HasSuffix(name, ".down.sql") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .sql files in %s", path)
+	}
+	slices.SortFunc(files, naturalCompare)
+	return files, nil
+}
+
+// naturalCompare 比较字符串，连续数字按数值比较
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := splitDigits(a)
+			numB, restB := splitDigits(b)
+			if c := len(numA) - len(numB); c != 0 {
+				return c
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+// splitDigits 拆分开头的数字（去掉前导零）与剩余部分
+func splitDigits(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	digits := strings.TrimLeft(s[:end], "0")
+	return digits, s[end:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"V2__a.sql", "V10__a.sql", -1},
+		{"V10__a.sql", "V2__a.sql", 1},
+		{"V002__a.sql", "V10__a.sql", -1},
+		{"V01__a.sql", "V1__a.sql", 0},
+		{"V1_2.sql", "V1_10.sql", -1},
+		{"a.sql", "b.sql", -1},
+		{"1", "1a", -1},
+		{"", "", 0},
+		{"abc", "abc", 0},
+	}
+	for _, tt := range tests {
+		got := naturalCompare(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("naturalCompare(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSQLFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"V10__index.sql",
+		"V2__user.SQL",
+		"V1__init.sql",
+		"V2__user.down.sql",
+		"README.md",
+		"sub/V3__order.sql",
+	} {
+		writeTestFile(t, filepath.Join(dir, name), "SELECT 1;")
+	}
+
+	files, err := sqlFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "V1__init.sql"),
+		filepath.Join(dir, "V2__user.SQL"),
+		filepath.Join(dir, "V10__index.sql"),
+		filepath.Join(dir, "sub", "V3__order.sql"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("sqlFiles(dir) = %q, want %q", files, want)
+	}
+
+	// 单个文件原样返回，不检查后缀
+	single := filepath.Join(dir, "README.md")
+	if files, err := sqlFiles(single); err != nil || !reflect.DeepEqual(files, []string{single}) {
+		t.Errorf("sqlFiles(file) = %q, %v", files, err)
+	}
+
+	empty := t.TempDir()
+	writeTestFile(t, filepath.Join(empty, "V1__init.down.sql"), "SELECT 1;")
+	if _, err := sqlFiles(empty); err == nil {
+		t.Error("sqlFiles(dir without .sql files) should fail")
+	}
+	if _, err := sqlFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("sqlFiles(missing) should fail")
+	}
+}
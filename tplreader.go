@@ -1,14 +1,19 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gobuffalo/packr/v2"
 
+	"github.com/jasonlabz/gentol/configx"
 	"github.com/jasonlabz/gentol/metadata"
 )
 
@@ -43,11 +48,14 @@ func IsDir(f string) bool {
 	return fi.IsDir()
 }
 
+// tplExt 模板目录中模板文件的扩展名，文件名为内置模板名称，如 model.tmpl
+const tplExt = ".tmpl"
+
 // LoadTemplate return template from template dir, falling back to the embedded templates
 func LoadTemplate(filename string, templateDir string) (tpl *metadata.Template, err error) {
-	baseName := filepath.Base(filename)
+	baseName := strings.TrimSuffix(filepath.Base(filename), tplExt)
 	if templateDir != "" {
-		fpath := filepath.Join("", filename)
+		fpath := filepath.Join(templateDir, filename)
 		var b []byte
 		b, err = os.ReadFile(fpath)
 		if err == nil {
@@ -67,6 +75,52 @@ func LoadTemplate(filename string, templateDir string) (tpl *metadata.Template,
 	return template, nil
 }
 
+// loadTpl 按名称加载模板，template_dir 中的同名 .tmpl 文件优先于内置模板
+func loadTpl(name string) (*metadata.Template, bool) {
+	tpl, err := LoadTemplate(name+tplExt, configx.TableConfigs.TemplateDir)
+	if err != nil {
+		return nil, false
+	}
+	return tpl, true
+}
+
+// templatesFingerprint 返回实际生效的全部模板内容的指纹，模板变化时据此判断需要重新生成
+func templatesFingerprint() string {
+	hash := sha256.New()
+	for _, name := range metadata.TplNames() {
+		tpl, _ := loadTpl(name)
+		hash.Write([]byte(name))
+		hash.Write([]byte(tpl.Content))
+	}
+	for _, output := range configx.TableConfigs.Outputs {
+		if tpl, err := loadOutputTpl(output.Template); err == nil {
+			hash.Write([]byte(tpl.Name))
+			hash.Write([]byte(tpl.Content))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// exportTemplates 将内置模板导出到 dir，作为自定义模板的起点；已存在的文件仅在 force 时覆盖
+func exportTemplates(dir string, force bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range metadata.TplNames() {
+		tpl, _ := metadata.LoadTpl(name)
+		fpath := filepath.Join(dir, name+tplExt)
+		if IsExist(fpath) && !force {
+			log.Printf("file is exist, skip: %s (use --force to overwrite)\n", fpath)
+			continue
+		}
+		if err := os.WriteFile(fpath, []byte(tpl.Content), 0644); err != nil {
+			return err
+		}
+		log.Printf("writing %s\n", fpath)
+	}
+	return nil
+}
+
 func init() {
 	// innerBox = packr.New("gentol", "./template")
 	// _, filename, _, ok := runtime.Caller(1)
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// setTemplateDir 设置 template_dir，测试结束后还原
+func setTemplateDir(t *testing.T, dir string) {
+	t.Helper()
+	saved := configx.TableConfigs.TemplateDir
+	t.Cleanup(func() { configx.TableConfigs.TemplateDir = saved })
+	configx.TableConfigs.TemplateDir = dir
+}
+
+func TestLoadTplTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "model"+tplExt), "package {{.ModelPackageName}}\n")
+	setTemplateDir(t, dir)
+
+	tpl, ok := loadTpl("model")
+	if !ok || tpl.Content != "package {{.ModelPackageName}}\n" {
+		t.Fatalf("loadTpl(model) = %+v, want the template in template_dir", tpl)
+	}
+	if want := "file://" + filepath.Join(dir, "model"+tplExt); tpl.Name != want {
+		t.Errorf("template name = %q, want %q", tpl.Name, want)
+	}
+
+	// 未覆盖的模板使用内置模板
+	builtin, _ := metadata.LoadTpl("dao")
+	if tpl, ok = loadTpl("dao"); !ok || tpl.Content != builtin.Content {
+		t.Error("loadTpl(dao) should fall back to the built-in template")
+	}
+	if _, ok = loadTpl("missing"); ok {
+		t.Error("loadTpl(missing) should fail")
+	}
+}
+
+func TestExportTemplates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	if err := exportTemplates(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	names := metadata.TplNames()
+	if len(names) == 0 {
+		t.Fatal("no built-in templates")
+	}
+	for _, name := range names {
+		builtin, _ := metadata.LoadTpl(name)
+		if got := readGenerated(t, filepath.Join(dir, name+tplExt)); got != builtin.Content {
+			t.Errorf("exported %s differs from the built-in template", name)
+		}
+	}
+
+	// 已存在的模板只在 force 时覆盖
+	modelFile := filepath.Join(dir, "model"+tplExt)
+	writeTestFile(t, modelFile, "custom\n")
+	if err := exportTemplates(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readGenerated(t, modelFile); got != "custom\n" {
+		t.Errorf("export without force overwrote the customized template: %q", got)
+	}
+	if err := exportTemplates(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readGenerated(t, modelFile); got == "custom\n" {
+		t.Error("export with force should overwrite the customized template")
+	}
+}
+
+// TestGeneratedWithTemplateDir 导出的模板原样使用时生成结果不变，修改后的模板用于生成
+func TestGeneratedWithTemplateDir(t *testing.T) {
+	const ddl = "CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)"
+	dir := generateFromSQLite(t, &configx.DBTableInfo{}, ddl)
+	builtinModel := readGenerated(t, filepath.Join(dir, "dal", "db", "model", "user.go"))
+
+	tplDir := filepath.Join(t.TempDir(), "templates")
+	if err := exportTemplates(tplDir, false); err != nil {
+		t.Fatal(err)
+	}
+	setTemplateDir(t, tplDir)
+	dir = generateFromSQLite(t, &configx.DBTableInfo{}, ddl)
+	if got := readGenerated(t, filepath.Join(dir, "dal", "db", "model", "user.go")); got != builtinModel {
+		t.Error("exported templates should generate the same model as the built-in ones")
+	}
+
+	content, err := os.ReadFile(filepath.Join(tplDir, "model"+tplExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := strings.Replace(string(content), "type {{.ModelStructName}} struct {", "// custom template\ntype {{.ModelStructName}} struct {", 1)
+	writeTestFile(t, filepath.Join(tplDir, "model"+tplExt), custom)
+	dir = generateFromSQLite(t, &configx.DBTableInfo{}, ddl)
+	if got := readGenerated(t, filepath.Join(dir, "dal", "db", "model", "user.go")); !strings.Contains(got, "// custom template\ntype User struct {") {
+		t.Errorf("user.go should be generated from the customized template:\n%s", got)
+	}
+	if dao := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "user_dao.go")); strings.Contains(dao, "custom template") {
+		t.Error("dao should still use its own template")
+	}
+}
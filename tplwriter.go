@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
-	"io"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"text/template"
 
 	"gorm.io/gorm"
@@ -18,41 +18,32 @@ import (
 	"github.com/jasonlabz/gentol/metadata"
 )
 
+// renderedFiles 记录当前表本次写出的文件，用于更新生成清单
+var renderedFiles []string
+
+// keepFile 记录已存在且不覆盖的文件（_ext、_hook），使其仍归属于当前表
+func keepFile(path string) {
+	abs, _ := filepath.Abs(path)
+	renderedFiles = append(renderedFiles, abs)
+}
+
 // RenderingTemplate rendering a template with data
 func RenderingTemplate(templateInfo *metadata.Template, dataGen metadata.IBaseData, outFilePath string, overwrite bool) (err error) {
-	var file *os.File
-	data := dataGen.GenRenderData()
-	ext := filepath.Ext(outFilePath)
-	dir := filepath.Dir(outFilePath)
-	if !IsExist(dir) {
-		_ = createDirectory(dir)
-	}
-	perm := fs.FileMode(0644)
-	if ext == ".sh" || ext == ".ps1" {
-		perm = fs.FileMode(0755)
-	}
-	if !IsExist(outFilePath) && !overwrite {
-		file, err = os.OpenFile(outFilePath, os.O_CREATE|os.O_RDWR, perm)
+	defer func() {
 		if err != nil {
-			log.Printf("open file error %s\n", err.Error())
-			return
-		}
-	} else {
-		if overwrite {
-			file, err = os.OpenFile(outFilePath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, perm)
-			if err != nil {
-				log.Printf("overwrite true: open file error %s\n", err.Error())
-				return
-			}
-		} else {
-			// skip
-			log.Printf("file is exist, please delete it before generate: %s\n", outFilePath)
-			return
+			genReport.Failed = append(genReport.Failed, outFilePath)
 		}
+	}()
+	data := dataGen.GenRenderData()
+	if IsExist(outFilePath) && !overwrite {
+		// skip
+		genReport.Skipped = append(genReport.Skipped, outFilePath)
+		log.Printf("file is exist, please delete it before generate: %s\n", outFilePath)
+		return
 	}
 	fileName := filepath.Base(outFilePath)
 
-	tmpl, err := template.New(fileName).Option("missingkey=error").Parse(templateInfo.Content)
+	tmpl, err := template.New(fileName).Funcs(metadata.FuncMap()).Option("missingkey=error").Parse(templateInfo.Content)
 	if err != nil {
 		return
 	}
@@ -62,18 +53,42 @@ func RenderingTemplate(templateInfo *metadata.Template, dataGen metadata.IBaseDa
 	if err != nil {
 		return fmt.Errorf("error in rendering %s: %s", templateInfo.Name, err.Error())
 	}
+	return writeRendered(templateInfo, buf.Bytes(), outFilePath)
+}
 
-	fileContents, err := Format(templateInfo, buf.Bytes(), outFilePath)
+// writeRendered 格式化渲染结果、保留保护区内容后写出文件
+func writeRendered(templateInfo *metadata.Template, content []byte, outFilePath string) (err error) {
+	ext := filepath.Ext(outFilePath)
+	perm := fs.FileMode(0644)
+	if ext == ".sh" || ext == ".ps1" {
+		perm = fs.FileMode(0755)
+	}
+
+	fileContents, err := Format(templateInfo, content, outFilePath)
 	if err != nil {
 		return fmt.Errorf("error writing %s - error: %v", outFilePath, err)
 	}
 
-	_, err = io.Copy(file, bytes.NewReader(fileContents))
+	// 保留已有文件中保护区的手写内容
+	if existing, readErr := os.ReadFile(outFilePath); readErr == nil {
+		if spliced := spliceRegions(outFilePath, fileContents, existing); !bytes.Equal(spliced, fileContents) {
+			fileContents, err = Format(templateInfo, spliced, outFilePath)
+			if err != nil {
+				return fmt.Errorf("error writing %s - error: %v", outFilePath, err)
+			}
+		}
+	}
+
+	written, err := writeGenerated(outFilePath, fileContents, perm)
 	if err != nil {
 		return fmt.Errorf("error writing %s - error: %v", outFilePath, err)
 	}
 
-	log.Printf("writing %s\n", outFilePath)
+	// 内容未变化的文件不重复输出，如每张表都会渲染的 base.go、db.go
+	if written {
+		log.Printf("writing %s\n", outFilePath)
+	}
+	renderedFiles = append(renderedFiles, outFilePath)
 
 	return nil
 }
@@ -116,47 +131,33 @@ func CRLFNewlines(d []byte) []byte {
 	return d
 }
 
-func WriteModel(dbInfo *configx.DBTableInfo, schemaName, tableName string,
-	columnTypes []gorm.ColumnType, indexs []gorm.Index) {
-	modelData := &metadata.ModelMeta{
-		ModelPackageName: func() string {
-			if dbInfo.ModelPath == "" {
-				dbInfo.ModelPath = "dal/db/model"
-			}
-			return metadata.ToLower(filepath.Base(dbInfo.ModelPath))
-		}(),
-		ModelStructName: metadata.UnderscoreToUpperCamelCase(tableName),
-	}
-	columnTempList := make([]*metadata.ColumnInfo, 0)
-	getColumnInfo(columnTypes, &columnTempList)
-	modelData.ColumnList = columnTempList
-	modelData.DBType = dbInfo.DBType
-	modelData.SchemaName = schemaName
-	modelData.TableName = tableName
-	modelData.Indexs = indexs
-	modelData.ModelPath = dbInfo.ModelPath
-	modelData.UseSQLNullable = dbInfo.UseSQLNullable
-	modelTpl, ok := metadata.LoadTpl("model")
+func WriteModel(dbInfo *configx.DBTableInfo, schemaName, tableName, tableComment string,
+	columnTypes []gorm.ColumnType, indexs []gorm.Index, readOnlyColumns []string) {
+	modelData := newModelMeta(dbInfo, schemaName, tableName, tableComment, columnTypes, indexs, readOnlyColumns)
+	modelTpl, ok := loadTpl("model")
 	if !ok {
 		log.Println("undefined template" + "model")
 		return
 	}
-	exist := IsExist(modelData.ModelPath)
-	if !exist {
-		_ = os.MkdirAll(modelData.ModelPath, 0666)
-	}
-	ff, _ := filepath.Abs(filepath.Join(modelData.ModelPath, modelData.TableName+".go"))
+	ensureDir(modelData.ModelPath)
+	ff, _ := filepath.Abs(filepath.Join(modelData.ModelPath, modelData.FileName+".go"))
 	err := RenderingTemplate(modelTpl, modelData, ff, true)
 	if err != nil {
 		log.Println("err occured: ", err)
 		return
 	}
 
-	hookFile := filepath.Join(modelData.ModelPath, modelData.TableName+"_hook.go")
-	exist = IsExist(hookFile)
-	if !exist && dbInfo.GenHook {
+	hookFile := filepath.Join(modelData.ModelPath, modelData.FileName+"_hook.go")
+	exist := IsExist(hookFile)
+	if exist && modelData.Audited && !hasAuditHooks(hookFile) {
+		log.Printf("hook file %s exists without audit hooks, delete it to regenerate\n", hookFile)
+	}
+	if exist {
+		keepFile(hookFile)
+	}
+	if !exist && (dbInfo.GenHook || modelData.Audited) {
 		ff, _ = filepath.Abs(hookFile)
-		modelHookTpl, ok := metadata.LoadTpl("model_hook")
+		modelHookTpl, ok := loadTpl("model_hook")
 		if !ok {
 			log.Println("undefined template" + "model_hook")
 			return
@@ -169,7 +170,7 @@ func WriteModel(dbInfo *configx.DBTableInfo, schemaName, tableName string,
 	}
 	baseFile := filepath.Join(modelData.ModelPath, "base.go")
 	ff, _ = filepath.Abs(baseFile)
-	modelBaseTpl, ok := metadata.LoadTpl("model_base")
+	modelBaseTpl, ok := loadTpl("model_base")
 	if !ok {
 		log.Println("undefined template" + "model_base")
 		return
@@ -182,36 +183,99 @@ func WriteModel(dbInfo *configx.DBTableInfo, schemaName, tableName string,
 	return
 }
 
-func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnTypes []gorm.ColumnType) {
-	daoData := &metadata.DaoMeta{
-		ModelPackageName: metadata.ToLower(filepath.Base(dbInfo.ModelPath)),
-		DaoPackageName:   metadata.ToLower(filepath.Base(dbInfo.DaoPath)),
-		ModelModulePath:  dbInfo.ModelModule,
-		DaoModulePath:    dbInfo.DaoModule,
-		ModelStructName:  metadata.UnderscoreToUpperCamelCase(tableName),
-	}
-	if dbInfo.DaoPath == "" {
-		dbInfo.DaoPath = "dal/db/dao"
+// newModelMeta 构建 model 模板的渲染数据
+func newModelMeta(dbInfo *configx.DBTableInfo, schemaName, tableName, tableComment string,
+	columnTypes []gorm.ColumnType, indexs []gorm.Index, readOnlyColumns []string) *metadata.ModelMeta {
+	modelData := &metadata.ModelMeta{
+		ModelPackageName: func() string {
+			if dbInfo.ModelPath == "" {
+				dbInfo.ModelPath = "dal/db/model"
+			}
+			return metadata.ToLower(filepath.Base(dbInfo.ModelPath))
+		}(),
+		ModelStructName: dbInfo.Naming.StructName(tableName),
 	}
 	columnTempList := make([]*metadata.ColumnInfo, 0)
 	getColumnInfo(columnTypes, &columnTempList)
-	daoData.ColumnList = columnTempList
-	daoData.DBType = dbInfo.DBType
-	daoData.SchemaName = schemaName
-	daoData.TableName = tableName
-	daoData.ModelPath = dbInfo.ModelPath
-	daoData.DaoPath = dbInfo.DaoPath
-	daoTpl, ok := metadata.LoadTpl("dao")
+	markReadOnlyColumns(columnTempList, readOnlyColumns)
+	modelData.ColumnList = columnTempList
+	modelData.DBType = dbInfo.DBType
+	modelData.SchemaName = schemaName
+	modelData.TableName = tableName
+	modelData.TableComment = tableComment
+	modelData.FileName = dbInfo.Naming.FileName(tableName, modelData.ModelStructName)
+	modelData.Indexs = indexs
+	modelData.ModelPath = dbInfo.ModelPath
+	modelData.UseSQLNullable = dbInfo.UseSQLNullable
+	modelData.JsonFormat = configx.TableConfigs.JsonFormat
+	modelData.ExtraTags = configx.TableConfigs.GetTags()
+	modelData.ValidateTag = configx.TableConfigs.ValidateTag
+	modelData.TenantMode = dbInfo.TenantMode
+	modelData.Audited = dbInfo.Audit.IsAudited(tableName)
+	modelData.AuditTableName = dbInfo.Audit.GetTableName()
+	return modelData
+}
+
+// WriteAudit 生成审计日志模型及对应数据库的建表语句
+func WriteAudit(dbInfo *configx.DBTableInfo) {
+	if dbInfo.ModelPath == "" {
+		dbInfo.ModelPath = "dal/db/model"
+	}
+	auditTableName := dbInfo.Audit.GetTableName()
+	auditData := &metadata.ModelMeta{
+		ModelPackageName: metadata.ToLower(filepath.Base(dbInfo.ModelPath)),
+		ModelStructName:  metadata.UnderscoreToUpperCamelCase(auditTableName),
+		AuditTableName:   auditTableName,
+	}
+	auditData.DBType = dbInfo.DBType
+	auditData.TableName = auditTableName
+	auditData.ModelPath = dbInfo.ModelPath
+
+	modelAuditTpl, ok := loadTpl("model_audit")
+	if !ok {
+		log.Println("undefined template" + "model_audit")
+		return
+	}
+	ff, _ := filepath.Abs(filepath.Join(auditData.ModelPath, auditTableName+".go"))
+	err := RenderingTemplate(modelAuditTpl, auditData, ff, true)
+	if err != nil {
+		log.Println("err occured: ", err)
+		return
+	}
+
+	auditDDLTpl, ok := loadTpl("audit_ddl")
+	if !ok {
+		log.Println("undefined template" + "audit_ddl")
+		return
+	}
+	ff, _ = filepath.Abs(filepath.Join(auditData.ModelPath, auditTableName+".sql"))
+	err = RenderingTemplate(auditDDLTpl, auditData, ff, true)
+	if err != nil {
+		log.Println("err occured: ", err)
+		return
+	}
+}
+
+// hasAuditHooks 判断已存在的 hook 文件是否包含审计钩子
+func hasAuditHooks(hookFile string) bool {
+	content, err := os.ReadFile(hookFile)
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(content, []byte("AuditBeforeChange"))
+}
+
+func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName, tableComment string, columnTypes []gorm.ColumnType) {
+	daoData := newDaoMeta(dbInfo, schemaName, tableName, tableComment, columnTypes)
+	daoTpl, ok := loadTpl("dao")
 	if !ok {
 		log.Println("undefined template" + "dao")
 		return
 	}
 	daoInterfacePath := daoData.DaoPath
-	if !IsExist(daoInterfacePath) {
-		_ = os.MkdirAll(daoInterfacePath, 0666)
-	}
+	ensureDir(daoInterfacePath)
 
-	ff, _ := filepath.Abs(filepath.Join(daoInterfacePath, daoData.TableName+"_dao.go"))
+	ff, _ := filepath.Abs(filepath.Join(daoInterfacePath, daoData.FileName+"_dao.go"))
 	err := RenderingTemplate(daoTpl, daoData, ff, true)
 	if err != nil {
 		log.Println("err occured: ", err)
@@ -219,9 +283,9 @@ func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnT
 	}
 
 	// dao扩展自定义文件，不覆盖
-	daoExtInterface, _ := filepath.Abs(filepath.Join(daoInterfacePath, daoData.TableName+"_dao_ext.go"))
+	daoExtInterface, _ := filepath.Abs(filepath.Join(daoInterfacePath, daoData.FileName+"_dao_ext.go"))
 	if !IsExist(daoExtInterface) {
-		daoExtTpl, ok := metadata.LoadTpl("daoExt")
+		daoExtTpl, ok := loadTpl("daoExt")
 		if !ok {
 			log.Println("undefined template" + "daoExt")
 			return
@@ -231,15 +295,15 @@ func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnT
 			log.Println("err occured: ", err)
 			return
 		}
+	} else {
+		keepFile(daoExtInterface)
 	}
 
 	implDir := filepath.Join(daoData.DaoPath, "impl")
-	if !IsExist(implDir) {
-		_ = os.MkdirAll(implDir, 0666)
-	}
-	daoImplFile := filepath.Join(implDir, daoData.TableName+"_dao_impl.go")
+	ensureDir(implDir)
+	daoImplFile := filepath.Join(implDir, daoData.FileName+"_dao_impl.go")
 	ff, _ = filepath.Abs(daoImplFile)
-	daoImplTpl, ok := metadata.LoadTpl("dao_impl")
+	daoImplTpl, ok := loadTpl("dao_impl")
 	if !ok {
 		log.Println("undefined template" + "dao_impl")
 		return
@@ -249,10 +313,10 @@ func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnT
 		log.Println("err occured: ", err)
 		return
 	}
-	daoExtImplFile := filepath.Join(implDir, daoData.TableName+"_dao_ext_impl.go")
+	daoExtImplFile := filepath.Join(implDir, daoData.FileName+"_dao_ext_impl.go")
 	ff, _ = filepath.Abs(daoExtImplFile)
 	if !IsExist(ff) {
-		daoExtImplTpl, ok := metadata.LoadTpl("daoExtImpl")
+		daoExtImplTpl, ok := loadTpl("daoExtImpl")
 		if !ok {
 			log.Println("undefined template" + "daoExtImpl")
 			return
@@ -262,12 +326,14 @@ func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnT
 			log.Println("err occured: ", err)
 			return
 		}
+	} else {
+		keepFile(ff)
 	}
 	// baseFile := filepath.Join(daoData.DaoPath, "impl", "db.go")
 	baseFile := filepath.Join(daoData.DaoPath, "db.go")
 	// baseFileExist := IsExist(baseFile)
 	ff, _ = filepath.Abs(baseFile)
-	daoBaseTpl, ok := metadata.LoadTpl("database")
+	daoBaseTpl, ok := loadTpl("database")
 	if !ok {
 		log.Println("undefined template" + "database")
 		return
@@ -280,6 +346,51 @@ func WriteDao(dbInfo *configx.DBTableInfo, schemaName, tableName string, columnT
 	return
 }
 
+// newDaoMeta 构建 dao 模板的渲染数据
+func newDaoMeta(dbInfo *configx.DBTableInfo, schemaName, tableName, tableComment string, columnTypes []gorm.ColumnType) *metadata.DaoMeta {
+	daoData := &metadata.DaoMeta{
+		ModelPackageName: metadata.ToLower(filepath.Base(dbInfo.ModelPath)),
+		DaoPackageName:   metadata.ToLower(filepath.Base(dbInfo.DaoPath)),
+		ModelModulePath:  dbInfo.ModelModule,
+		DaoModulePath:    dbInfo.DaoModule,
+		ModelStructName:  dbInfo.Naming.StructName(tableName),
+	}
+	if dbInfo.DaoPath == "" {
+		dbInfo.DaoPath = "dal/db/dao"
+	}
+	columnTempList := make([]*metadata.ColumnInfo, 0)
+	getColumnInfo(columnTypes, &columnTempList)
+	daoData.ColumnList = columnTempList
+	daoData.DBType = dbInfo.DBType
+	daoData.SchemaName = schemaName
+	daoData.TableName = tableName
+	daoData.TableComment = tableComment
+	daoData.FileName = dbInfo.Naming.FileName(tableName, daoData.ModelStructName)
+	daoData.ModelPath = dbInfo.ModelPath
+	daoData.DaoPath = dbInfo.DaoPath
+	daoData.ReadWriteSplit = dbInfo.ReadWriteSplit
+	daoData.UseOtel = dbInfo.UseOtel
+	daoData.TenantMode = dbInfo.TenantMode
+	daoData.Audited = dbInfo.Audit.IsAudited(tableName)
+	daoData.Sharding = len(dbInfo.Shards) > 0
+	if shard := dbInfo.GetShard(tableName); shard != nil {
+		daoData.ShardFormat = shard.GetFormat()
+	}
+	return daoData
+}
+
+// markReadOnlyColumns 标记数据库生成值的只读字段
+func markReadOnlyColumns(columnInfoList []*metadata.ColumnInfo, readOnlyColumns []string) {
+	for _, columnInfo := range columnInfoList {
+		for _, column := range readOnlyColumns {
+			if strings.EqualFold(columnInfo.ColumnName, column) {
+				columnInfo.ReadOnly = true
+				break
+			}
+		}
+	}
+}
+
 func getColumnInfo(columnTypes []gorm.ColumnType, columnInfoList *[]*metadata.ColumnInfo) {
 	for _, columnType := range columnTypes {
 		*columnInfoList = append(*columnInfoList, &metadata.ColumnInfo{
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/jasonlabz/gentol/configx"
+	"github.com/jasonlabz/gentol/metadata"
+)
+
+// generateFromSQLite 在临时模块中按 ddl 建立 SQLite 库，并按 dbInfo 生成代码；返回模块目录，工作目录在测试期间切换到该目录
+func generateFromSQLite(t *testing.T, dbInfo *configx.DBTableInfo, ddl ...string) string {
+	t.Helper()
+	dir := prepareGenerate(t)
+	dbPath := filepath.Join(dir, "app.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range ddl {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+
+	// 连接按库名缓存，每个测试使用独立的库名
+	dbInfo.DBName, dbInfo.DBType, dbInfo.DSN = dbPath, "sqlite", dbPath
+	generate(t, dbInfo)
+	return dir
+}
+
+// generateFromSQL 与 generateFromSQLite 相同，但从建表 SQL 文件读取表结构，方言由 dbInfo.DBType 决定，用于 SQLite 不支持的注释等
+func generateFromSQL(t *testing.T, dbInfo *configx.DBTableInfo, ddl string) string {
+	t.Helper()
+	dir := prepareGenerate(t)
+	sqlPath := filepath.Join(dir, "schema.sql")
+	writeTestFile(t, sqlPath, ddl)
+	genOptions.SQLPath = sqlPath
+	dbInfo.DBName = "app"
+	generate(t, dbInfo)
+	return dir
+}
+
+// prepareGenerate 还原测试修改的全局状态，并切换到新建的临时模块目录
+func prepareGenerate(t *testing.T) string {
+	t.Helper()
+	resetManifest(t)
+	saved := *configx.TableConfigs
+	t.Cleanup(func() { *configx.TableConfigs = saved })
+
+	dir := chdirTemp(t)
+	writeTestModule(t, dir)
+	return dir
+}
+
+func generate(t *testing.T, dbInfo *configx.DBTableInfo) {
+	t.Helper()
+	dbInfo.ModelPath, dbInfo.DaoPath = "dal/db/model", "dal/db/dao"
+	processDatabaseConfig(dbInfo, "")
+	if len(genReport.Failed) > 0 {
+		t.Fatalf("generate failed: %q", genReport.Failed)
+	}
+}
+
+// readGenerated 读取生成的文件
+func readGenerated(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// runGeneratedDBTests 只保留生成的 dao/db.go 运行 test；表的 model、dao 依赖的 null 等包在测试环境中不可用
+func runGeneratedDBTests(t *testing.T, dir, test string) {
+	t.Helper()
+	daoDir := filepath.Join(dir, "dal", "db", "dao")
+	testDir := filepath.Join(t.TempDir(), "dao")
+	writeTestFile(t, filepath.Join(testDir, "db.go"), readGenerated(t, filepath.Join(daoDir, "db.go")))
+	writeTestFile(t, filepath.Join(testDir, "db_test.go"), test)
+	runGeneratedTests(t, filepath.Dir(testDir))
+}
+
+// daoMethodBodies 按方法名拆分生成的 DAO 实现
+func daoMethodBodies(impl string) map[string]string {
+	bodies := make(map[string]string)
+	for _, chunk := range strings.Split(impl, "\nfunc (")[1:] {
+		name := chunk[strings.Index(chunk, ") ")+2:]
+		name = name[:strings.Index(name, "(")]
+		bodies[name] = chunk
+	}
+	return bodies
+}
+
+// readWriteSplitTest 读连接的路由：未注册从库、WithPrimary 时走主库，否则轮询从库
+const readWriteSplitTest = `package dao
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestReadDB(t *testing.T) {
+	primary, replica1, replica2 := &gorm.DB{}, &gorm.DB{}, &gorm.DB{}
+	SetGormDB(primary)
+	ctx := context.Background()
+	if ReadDB(ctx) != primary {
+		t.Fatal("ReadDB without replicas should return the primary")
+	}
+
+	SetReplicaDBs(replica1, replica2)
+	seen := map[*gorm.DB]int{}
+	for i := 0; i < 4; i++ {
+		seen[ReadDB(ctx)]++
+	}
+	if seen[replica1] != 2 || seen[replica2] != 2 {
+		t.Errorf("ReadDB should round-robin over replicas, got %d and %d of 4", seen[replica1], seen[replica2])
+	}
+	if ReadDB(WithPrimary(ctx)) != primary {
+		t.Error("ReadDB with WithPrimary should return the primary")
+	}
+
+	SetReplicaDBs()
+	if ReadDB(ctx) != primary {
+		t.Error("ReadDB after removing replicas should return the primary")
+	}
+}
+`
+
+// TestGeneratedReadWriteSplit 开启 read_write_split 后查询经 readTx 路由到从库，写入经 tx 走主库
+func TestGeneratedReadWriteSplit(t *testing.T) {
+	const ddl = "CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)"
+	dir := generateFromSQLite(t, &configx.DBTableInfo{ReadWriteSplit: true}, ddl)
+	impl := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "impl", "user_dao_impl.go"))
+	methods := daoMethodBodies(impl)
+	for _, name := range []string{"SelectByRawSQL", "SelectAll", "SelectOneByPrimaryKey", "SelectRecordByCondition",
+		"SelectPageRecordByCondition", "CountByCondition"} {
+		if !strings.Contains(methods[name], "u.readTx(ctx)") {
+			t.Errorf("%s should read through readTx", name)
+		}
+	}
+	for _, name := range []string{"Insert", "BatchInsert", "UpdateByPrimaryKey", "UpsertRecord", "DeleteByPrimaryKey"} {
+		if body := methods[name]; !strings.Contains(body, "u.tx(ctx)") || strings.Contains(body, "readTx") {
+			t.Errorf("%s should write through tx", name)
+		}
+	}
+	if !strings.Contains(methods["readTx"], "dao.TxFromContext(ctx)") {
+		t.Error("readTx should keep using the transaction in context")
+	}
+	runGeneratedDBTests(t, dir, readWriteSplitTest)
+
+	// 未开启时不生成路由
+	dir = generateFromSQLite(t, &configx.DBTableInfo{}, ddl)
+	if db := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "db.go")); strings.Contains(db, "ReadDB") {
+		t.Errorf("db.go without read_write_split defines the router:\n%s", db)
+	}
+	if impl = readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "impl", "user_dao_impl.go")); strings.Contains(impl, "readTx") {
+		t.Error("dao without read_write_split reads through readTx")
+	}
+}
+
+// transactionTest 嵌套的 RunTransaction 加入外层事务，RunTransactionWithSavepoint 只回滚到保存点
+const transactionTest = `package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jasonlabz/sqlite"
+	"gorm.io/gorm"
+)
+
+var errRollback = errors.New("rollback")
+
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err = db.Exec("CREATE TABLE item (name TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	SetGormDB(db)
+	return db
+}
+
+func insert(ctx context.Context, name string) error {
+	tx, _ := TxFromContext(ctx)
+	return tx.Exec("INSERT INTO item VALUES (?)", name).Error
+}
+
+func names(t *testing.T, db *gorm.DB) []string {
+	var result []string
+	if err := db.Table("item").Order("name").Pluck("name", &result).Error; err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestTxFromContext(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	if _, ok := TxFromContext(ctx); ok {
+		t.Error("TxFromContext outside a transaction should report false")
+	}
+	// 其他包使用字符串键存入的连接不会被当作事务
+	if _, ok := TxFromContext(context.WithValue(ctx, "transactionDB", db)); ok {
+		t.Error("TxFromContext should ignore untyped keys")
+	}
+}
+
+func TestNestedRunTransaction(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	err := RunTransaction(ctx, func(ctx context.Context) error {
+		outer, _ := TxFromContext(ctx)
+		if err := insert(ctx, "outer"); err != nil {
+			return err
+		}
+		return RunTransaction(ctx, func(ctx context.Context) error {
+			if inner, _ := TxFromContext(ctx); inner != outer {
+				t.Error("nested RunTransaction should join the outer transaction")
+			}
+			if err := insert(ctx, "inner"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("RunTransaction = %v, want the inner error", err)
+	}
+	if got := names(t, db); len(got) != 0 {
+		t.Errorf("rows after rollback = %q, want none", got)
+	}
+
+	if err = RunTransaction(ctx, func(ctx context.Context) error {
+		if err := insert(ctx, "outer"); err != nil {
+			return err
+		}
+		return RunTransaction(ctx, func(ctx context.Context) error { return insert(ctx, "inner") })
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(t, db); len(got) != 2 {
+		t.Errorf("rows after commit = %q, want inner and outer", got)
+	}
+}
+
+func TestRunTransactionWithSavepoint(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	err := RunTransaction(ctx, func(ctx context.Context) error {
+		if err := insert(ctx, "outer"); err != nil {
+			return err
+		}
+		err := RunTransactionWithSavepoint(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, "inner"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Errorf("RunTransactionWithSavepoint = %v, want the inner error", err)
+		}
+		return insert(ctx, "after")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(t, db); len(got) != 2 || got[0] != "after" || got[1] != "outer" {
+		t.Errorf("rows = %q, want after and outer", got)
+	}
+}
+`
+
+// TestGeneratedTransaction 生成的 DAO 通过类型化的上下文键获取事务，嵌套事务加入外层事务
+func TestGeneratedTransaction(t *testing.T) {
+	dir := generateFromSQLite(t, &configx.DBTableInfo{}, "CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)")
+	impl := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "impl", "user_dao_impl.go"))
+	if tx := daoMethodBodies(impl)["tx"]; !strings.Contains(tx, "dao.TxFromContext(ctx)") {
+		t.Errorf("tx should look up the transaction by TxFromContext:\n%s", tx)
+	}
+	if strings.Contains(impl, `"transactionDB"`) {
+		t.Error("dao should not use the untyped transactionDB key")
+	}
+	runGeneratedDBTests(t, dir, transactionTest)
+}
+
+// TestGeneratedOtel 开启 use_otel 后每个 DAO 方法以自身名称标记上下文，主库、从库连接注册链路与指标回调
+func TestGeneratedOtel(t *testing.T) {
+	const ddl = "CREATE TABLE user (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL)"
+	dir := generateFromSQLite(t, &configx.DBTableInfo{UseOtel: true, ReadWriteSplit: true}, ddl)
+	impl := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "impl", "user_dao_impl.go"))
+	methods := daoMethodBodies(impl)
+	count := 0
+	for name, body := range methods {
+		if name == "tx" || name == "readTx" {
+			continue
+		}
+		count++
+		if !strings.Contains(body, `ctx = dao.WithDaoMethod(ctx, "UserDao.`+name+`")`) {
+			t.Errorf("%s should record its method name in context", name)
+		}
+	}
+	if count < 10 {
+		t.Fatalf("found %d dao methods, want all of UserDao", count)
+	}
+
+	db := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "db.go"))
+	for _, want := range []string{
+		`"go.opentelemetry.io/otel"`,
+		`instrumentationName = "gentoltest/dal/db/dao"`,
+		"func WithDaoMethod(ctx context.Context, method string) context.Context",
+		`Register("gentol:otel_before_query", beforeTelemetry("select"))`,
+		`Register("gentol:otel_after_create", afterTelemetry("create"))`,
+	} {
+		if !strings.Contains(db, want) {
+			t.Errorf("db.go should contain %s", want)
+		}
+	}
+	if n := strings.Count(db, "registerTelemetry(db); err != nil"); n != 2 {
+		t.Errorf("registerTelemetry is called %d times, want in SetGormDB and SetReplicaDBs", n)
+	}
+
+	// 未开启时不引入 OpenTelemetry
+	dir = generateFromSQLite(t, &configx.DBTableInfo{}, ddl)
+	if db = readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "db.go")); strings.Contains(db, "opentelemetry") {
+		t.Errorf("db.go without use_otel imports OpenTelemetry:\n%s", db)
+	}
+	if impl = readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "impl", "user_dao_impl.go")); strings.Contains(impl, "WithDaoMethod") {
+		t.Error("dao without use_otel records the method name")
+	}
+}
+
+// TestGeneratedComments 表注释生成 model、dao 的文档注释，多行列注释生成字段上方的注释，主键注释列在 dao 方法的参数说明中
+func TestGeneratedComments(t *testing.T) {
+	dir := generateFromSQL(t, &configx.DBTableInfo{DBType: "mysql"}, "CREATE TABLE `user` (\n"+
+		"  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '用户 ID',\n"+
+		"  `status` tinyint NOT NULL COMMENT '状态\\n0 禁用\\n\\n1 启用',\n"+
+		"  `name` varchar(64) COMMENT '  名称  ',\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		") COMMENT='用户表\\n记录注册用户';\n")
+	model := readGenerated(t, filepath.Join(dir, "dal", "db", "model", "user.go"))
+	for _, want := range []string{
+		"// User 用户表\n// 记录注册用户\n//\n// User struct is mapping to the user table\ntype User struct {",
+		"\t// 状态\n\t// 0 禁用\n\t// 1 启用\n\tStatus int16 `",
+		"// Comment: 用户 ID\n",
+		"// Comment: 名称\n",
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("user.go should contain %q", want)
+		}
+	}
+	if strings.Contains(model, "Comment: 状态") {
+		t.Error("a multi-line column comment should not be repeated at the end of the field")
+	}
+
+	dao := readGenerated(t, filepath.Join(dir, "dal", "db", "dao", "user_dao.go"))
+	for _, want := range []string{
+		"// UserDao 用户表\n// 记录注册用户\n//\n// UserDao user 表数据访问接口\ntype UserDao interface {",
+		"// SelectOneByPrimaryKey 通过主键查询记录\n\t//   - id: 用户 ID\n",
+		"// DeleteByPrimaryKey 通过主键删除记录，返回删除记录数量\n\t//   - id: 用户 ID\n",
+	} {
+		if !strings.Contains(dao, want) {
+			t.Errorf("user_dao.go should contain %q", want)
+		}
+	}
+}
+
+func TestMarkReadOnlyColumns(t *testing.T) {
+	columns := []*metadata.ColumnInfo{{ColumnName: "id"}, {ColumnName: "FULL_NAME"}, {ColumnName: "version"}}
+	markReadOnlyColumns(columns, []string{"full_name", "row_version"})
+	for _, column := range columns {
+		if want := column.ColumnName == "FULL_NAME"; column.ReadOnly != want {
+			t.Errorf("%s.ReadOnly = %v, want %v", column.ColumnName, column.ReadOnly, want)
+		}
+	}
+}
+
+// TestGeneratedReadOnlyColumns 生成列（VIRTUAL、STORED）生成只读的 gorm 标签，普通列不受影响
+func TestGeneratedReadOnlyColumns(t *testing.T) {
+	dir := generateFromSQLite(t, &configx.DBTableInfo{OnlyModel: true}, "CREATE TABLE person ("+
+		"id INTEGER PRIMARY KEY, first_name TEXT NOT NULL, last_name TEXT NOT NULL, "+
+		"full_name TEXT GENERATED ALWAYS AS (first_name || ' ' || last_name) VIRTUAL, "+
+		"name_len INTEGER GENERATED ALWAYS AS (length(first_name)) STORED)")
+	model := readGenerated(t, filepath.Join(dir, "dal", "db", "model", "person.go"))
+	for column, readOnly := range map[string]bool{"id": false, "first_name": false, "last_name": false, "full_name": true, "name_len": true} {
+		if got := strings.Contains(model, `gorm:"->;column:`+column+`;`); got != readOnly {
+			t.Errorf("%s read only = %v, want %v", column, got, readOnly)
+		}
+	}
+}
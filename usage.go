@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 // topLevelUsage 顶层帮助信息：列出所有子命令及简要说明
 const topLevelUsage = `gentol - GORM model/dao code generator and project scaffolding tool
@@ -10,7 +13,12 @@ Usage:
   gentol new <module_path>        create a new project from template
   gentol init <module_path>       alias of "new"
   gentol update [module_path]     update an existing project from template
+  gentol check [flags]            fail if generated code is missing or stale (for CI)
+  gentol inspect [flags]          dump the introspected schema as JSON or YAML
+  gentol templates export [dir]   write the built-in templates to dir for customization
   gentol ddl <sql_file> [flags]   validate and execute DDL statements from a SQL file
+  gentol ddl export [dir ...]     write CREATE TABLE DDL for the gorm models in Go packages
+  gentol ddl translate <file ...> convert CREATE TABLE DDL from one database dialect to another
   gentol help | -h | --help       show this help message
 
 Run 'gentol <command> -h' for details on a specific command.
@@ -47,12 +55,64 @@ Flags:
       --template_branch=value branch of the template repo to clone (default: remote default branch)
 `
 
+// checkUsage check 子命令帮助信息
+const checkUsage = `Usage: gentol check [flags]
+
+Introspect the database, render all model/dao files in memory and compare
+them with the files on disk. Nothing is written. Exits with status 1 and
+lists every missing or stale file, so CI can catch a schema change that was
+not followed by a regeneration.
+
+Accepts the same flags as the default db-generation mode (or ./conf/table.yaml).
+
+Flags:
+      --diff                  also print a unified diff of the stale files
+`
+
+// inspectUsage inspect 子命令帮助信息
+const inspectUsage = `Usage: gentol inspect [flags]
+
+Connect to the database with the same flags as the default db-generation
+mode (or ./conf/table.yaml) and dump every selected table: columns with the
+raw database type and the mapped Go type, nullability, defaults, primary
+keys, indexes and comments. Shard tables are listed individually. Useful to
+feed other tools and to debug type mapping problems.
+
+Flags:
+      --format=value          output format [json | yaml] (default: json, or
+                              yaml when --output ends with .yaml/.yml)
+  -o, --output=value          write to file instead of stdout
+`
+
+// templatesUsage templates 子命令帮助信息
+const templatesUsage = `Usage: gentol templates export [dir] [flags]
+
+Write every built-in template to dir (default: ./templates) as <name>.tmpl,
+as a starting point for customization. Point template_dir in table.yaml (or
+--template_dir) at the directory; any file found there overrides the
+built-in template of the same name, missing files fall back to the built-in.
+
+Arguments:
+  dir                       output directory, default ./templates
+
+Flags:
+      --force                 overwrite existing files
+`
+
 // ddlUsage ddl 子命令帮助信息
 const ddlUsage = `Usage: gentol ddl <sql_file> [flags]
+       gentol ddl export [dir ...] [flags]
+       gentol ddl translate <file ...> [flags]
 
 Validate that a SQL file contains only DDL statements (CREATE, ALTER, DROP,
 TRUNCATE, RENAME, COMMENT), then execute them against the target database.
 
+Subcommands:
+  export                    write CREATE TABLE DDL for the gorm models in Go packages
+  translate                 convert CREATE TABLE DDL from one database dialect to another
+
+Run 'gentol ddl export -h' or 'gentol ddl translate -h' for their flags.
+
 Arguments:
   sql_file                  path to a .sql file containing DDL statements
 
@@ -67,6 +127,44 @@ Flags:
       --schema=value          schema name
 `
 
+// ddlExportUsage ddl export 子命令帮助信息
+const ddlExportUsage = `Usage: gentol ddl export [dir ...] [flags]
+
+Parse the Go files in each dir (default: current directory; dir/... includes
+subdirectories) and write CREATE TABLE, CREATE INDEX and COMMENT statements
+for every struct with a TableName() method or gorm tags, including models
+generated by gentol. Columns, primary keys, auto increment, defaults, unique
+constraints and indexes are read from the gorm tags the same way gorm does;
+type tags are translated to the target database where possible.
+
+Arguments:
+  dir                       directory of Go files
+
+Flags:
+      --db_type=value         target database type [mysql, postgres, greenplum, sqlserver, oracle, dm, sqlite]
+  -o, --output=value          write to file instead of stdout
+`
+
+// ddlTranslateUsage ddl translate 子命令帮助信息
+const ddlTranslateUsage = `Usage: gentol ddl translate <file|dir ...> --from=<db_type> --to=<db_type> [flags]
+
+Parse the CREATE TABLE, ALTER TABLE, CREATE INDEX and COMMENT ON statements in
+the SQL files (a directory is read in migration order, skipping *.down.sql)
+and write the resulting tables for the target database: column types,
+defaults, auto increment, primary keys, indexes and comments are converted to
+the target syntax. Constructs that cannot be translated (foreign keys, CHECK
+constraints, ON UPDATE, table options, types without an equivalent, other
+statements) are listed on stderr.
+
+Arguments:
+  file|dir                  SQL file or directory of SQL files
+
+Flags:
+      --from=value            source database type [mysql, postgres, greenplum, dm]
+      --to=value              target database type [mysql, postgres, greenplum, sqlserver, oracle, dm, sqlite]
+  -o, --output=value          write to file instead of stdout
+`
+
 // isHelpFlag 判断参数是否为帮助标志
 func isHelpFlag(arg string) bool {
 	return arg == "-h" || arg == "--help" || arg == "help"
@@ -92,5 +190,5 @@ func printSubUsage(usage string) {
 
 // printSubcommandHint 在默认模式 usage 前提示子命令的存在
 func printSubcommandHint() {
-	fmt.Println("Tip: run 'gentol -h' to see all subcommands (new, init, update, ddl).")
+	fmt.Fprintln(os.Stderr, "Tip: run 'gentol -h' to see all subcommands (new, init, update, check, inspect, templates, ddl).")
 }